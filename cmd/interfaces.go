@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fuskovic/networker/v3/internal/encoder"
+	"github.com/fuskovic/networker/v3/internal/iface"
+	"github.com/fuskovic/networker/v3/internal/usage"
+)
+
+func init() {
+	Root.AddCommand(interfacesCmd)
+}
+
+var interfacesCmd = &cobra.Command{
+	Use:     "interfaces",
+	Aliases: []string{"if"},
+	Short:   "List network interfaces with their addresses, mtu and traffic counters.",
+	Example: `
+# List every network interface:
+
+	nw interfaces
+
+# List a single interface and output as json:
+
+	nw if eth0 -o json
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var name string
+		if len(args) == 1 {
+			name = args[0]
+		}
+
+		ifaces, err := iface.List(name)
+		if err != nil {
+			usage.Fatalf(cmd, "failed to list interfaces: %s", err)
+		}
+
+		enc := encoder.New[iface.Interface](os.Stdout, output)
+		if err := enc.Encode(ifaces...); err != nil {
+			usage.Fatalf(cmd, "failed to encode interfaces: %s", err)
+		}
+	},
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fuskovic/networker/v3/internal/encoder"
+	"github.com/fuskovic/networker/v3/internal/inventory"
+	"github.com/fuskovic/networker/v3/internal/labels"
+	"github.com/fuskovic/networker/v3/internal/list"
+	"github.com/fuskovic/networker/v3/internal/usage"
+)
+
+var inventoryTags []string
+
+func init() {
+	inventoryListCmd.Flags().StringSliceVar(&inventoryTags, "tag", nil, "Only list devices labeled with any of the tags(see devices.yaml in the networker config directory).")
+	inventoryCmd.AddCommand(inventoryListCmd)
+	inventoryCmd.AddCommand(inventoryShowCmd)
+	inventoryCmd.AddCommand(inventoryHistoryCmd)
+	inventoryCmd.AddCommand(inventoryRemoveCmd)
+	Root.AddCommand(inventoryCmd)
+}
+
+var inventoryCmd = &cobra.Command{
+	Use:     "inventory",
+	Aliases: []string{"inv"},
+	Short:   "Query the devices that have been seen on the network.",
+	Example: `
+# Every run of nw list records the devices it finds in the inventory.
+
+# List every device that has been seen on the network:
+
+	nw inventory list
+
+# Show when a device first joined the network:
+
+	nw inventory show 00:11:32:aa:bb:cc
+
+# Show which ip addresses and hostnames a device has had:
+
+	nw inventory history 00:11:32:aa:bb:cc
+
+# Forget a device:
+
+	nw inventory remove 00:11:32:aa:bb:cc
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Usage()
+	},
+}
+
+var inventoryListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List every device that has been seen on the network.",
+	Example: `
+# List every device that has been seen on the network:
+
+	nw inventory list
+
+# List every device that has been seen on the network(short-hand) and output as json:
+
+	nw inv ls -o json
+
+# List every device labeled with a tag that has been seen on the network:
+
+	nw inventory list --tag critical
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		inv, err := inventory.Load()
+		if err != nil {
+			usage.Fatalf(cmd, "failed to load inventory: %s", err)
+		}
+
+		records := inv.Records()
+		labelRecords(loadLabels(cmd), records)
+		if len(inventoryTags) > 0 {
+			records = slices.DeleteFunc(records, func(r inventory.Record) bool {
+				return !labels.HasAnyTag(r.Tags, inventoryTags)
+			})
+		}
+
+		enc := encoder.New[inventory.Record](os.Stdout, output)
+		if err := enc.Encode(records...); err != nil {
+			usage.Fatalf(cmd, "failed to encode devices: %s", err)
+		}
+	},
+}
+
+var inventoryShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show a device by its hardware address, ip address, hostname or label.",
+	Example: `
+# Show a device by its hardware address:
+
+	nw inventory show 00:11:32:aa:bb:cc
+
+# Show a device by its label:
+
+	nw inventory show nas
+
+# Show a device by its ip address and output as json(includes its ip address and hostname history):
+
+	nw inventory show 192.168.1.23 -o json
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		record := lookupInventoryRecord(cmd, args[0])
+		enc := encoder.New[inventory.Record](os.Stdout, output)
+		if err := enc.Encode(*record); err != nil {
+			usage.Fatalf(cmd, "failed to encode device: %s", err)
+		}
+	},
+}
+
+var inventoryHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the ip addresses and hostnames a device has had.",
+	Example: `
+# Show the ip addresses and hostnames a device has had:
+
+	nw inventory history 00:11:32:aa:bb:cc
+
+# Show the ip addresses and hostnames the device currently named printer.lan has had:
+
+	nw inventory history printer.lan.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		record := lookupInventoryRecord(cmd, args[0])
+		enc := encoder.New[inventory.Change](os.Stdout, output)
+		if err := enc.Encode(record.History()...); err != nil {
+			usage.Fatalf(cmd, "failed to encode history: %s", err)
+		}
+	},
+}
+
+var inventoryRemoveCmd = &cobra.Command{
+	Use:     "remove",
+	Aliases: []string{"rm"},
+	Short:   "Forget a device.",
+	Example: `
+# Forget a device:
+
+	nw inventory remove 00:11:32:aa:bb:cc
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		inv, err := inventory.Load()
+		if err != nil {
+			usage.Fatalf(cmd, "failed to load inventory: %s", err)
+		}
+
+		if !inv.Remove(args[0]) {
+			usage.Fatalf(cmd, "no device found matching %q", args[0])
+		}
+
+		if err := inv.Save(); err != nil {
+			usage.Fatalf(cmd, "failed to save inventory: %s", err)
+		}
+		fmt.Printf("removed %s\n", args[0])
+	},
+}
+
+func lookupInventoryRecord(cmd *cobra.Command, query string) *inventory.Record {
+	inv, err := inventory.Load()
+	if err != nil {
+		usage.Fatalf(cmd, "failed to load inventory: %s", err)
+	}
+
+	ls := loadLabels(cmd)
+	record := inv.Lookup(query)
+	if l := ls.Find(query); record == nil && l != nil {
+		record = inv.Lookup(l.MAC)
+		if record == nil {
+			record = inv.Lookup(l.IP)
+		}
+	}
+	if record == nil {
+		usage.Fatalf(cmd, "no device found matching %q", query)
+	}
+
+	r := []inventory.Record{*record}
+	labelRecords(ls, r)
+	return &r[0]
+}
+
+// recordDevices records the devices that are present on the network in the inventory.
+func recordDevices(devices []list.Device) error {
+	inv, err := inventory.Load()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, d := range devices {
+		if !d.Present() {
+			continue
+		}
+
+		s := inventory.Sighting{
+			MAC:    d.MAC,
+			IP:     d.LocalIP.String(),
+			Vendor: d.Vendor,
+			Kind:   string(d.Kind),
+		}
+		if d.Hostname != "N/A" {
+			s.Hostname = d.Hostname
+		}
+		inv.Observe(s, now)
+	}
+	return inv.Save()
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/fuskovic/networker/v3/internal/inventory"
+	"github.com/fuskovic/networker/v3/internal/labels"
+	"github.com/fuskovic/networker/v3/internal/list"
+	"github.com/fuskovic/networker/v3/internal/usage"
+)
+
+func loadLabels(cmd *cobra.Command) labels.Labels {
+	ls, err := labels.Load()
+	if err != nil {
+		usage.Fatalf(cmd, "failed to load device labels: %s", err)
+	}
+	return ls
+}
+
+// labelDevices sets the label, owner and tags of each device in ls.
+func labelDevices(ls labels.Labels, devices []list.Device) {
+	for i := range devices {
+		if l := ls.Lookup(devices[i].MAC, devices[i].LocalIP.String()); l != nil {
+			devices[i].Label = l.Name
+			devices[i].Owner = l.Owner
+			devices[i].Tags = l.Tags
+		}
+	}
+}
+
+// labelRecords sets the label, owner and tags of each inventory record in ls.
+func labelRecords(ls labels.Labels, records []inventory.Record) {
+	for i := range records {
+		if l := ls.Lookup(records[i].MAC, records[i].IP); l != nil {
+			records[i].Label = l.Name
+			records[i].Owner = l.Owner
+			records[i].Tags = l.Tags
+		}
+	}
+}
@@ -2,18 +2,72 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"os/signal"
 	"slices"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/fuskovic/networker/v3/internal/classify"
 	"github.com/fuskovic/networker/v3/internal/encoder"
+	"github.com/fuskovic/networker/v3/internal/inventory"
+	"github.com/fuskovic/networker/v3/internal/labels"
 	"github.com/fuskovic/networker/v3/internal/list"
+	"github.com/fuskovic/networker/v3/internal/oui"
+	"github.com/fuskovic/networker/v3/internal/ping"
+	"github.com/fuskovic/networker/v3/internal/publicip"
 	"github.com/fuskovic/networker/v3/internal/spinner"
 	"github.com/fuskovic/networker/v3/internal/usage"
+	"github.com/fuskovic/networker/v3/internal/watch"
+)
+
+var (
+	listCIDR          string
+	listInterface     string
+	listAllInterfaces bool
+	noPublicIP        bool
+	publicIPEndpoint  string
+	stunServer        string
+	listMethod        string
+	listMDNS          bool
+	listSSDP          bool
+	listNetBIOS       bool
+	listLLMNR         bool
+	listOS            bool
+	listInventory     bool
+	listIPv6          bool
+	listCount         int
+	listTags          []string
+	watchInterval     time.Duration
+	watchLogFile      string
+	watchExec         string
+	watchWebhook      string
 )
 
 func init() {
+	listCmd.PersistentFlags().StringVar(&listCIDR, "cidr", "", "Subnet to sweep(defaults to the subnet of the local interface).")
+	listCmd.PersistentFlags().StringVarP(&listInterface, "interface", "i", "", "Interface to list devices on(defaults to the interface of the default route).")
+	listCmd.PersistentFlags().BoolVar(&listAllInterfaces, "all-interfaces", false, "List devices on every network the host is attached to. Networks that can't be swept are skipped.")
+	listCmd.PersistentFlags().BoolVar(&noPublicIP, "no-public-ip", false, "Skip looking up the public ip of the current device.")
+	listCmd.PersistentFlags().StringVar(&publicIPEndpoint, "public-ip-endpoint", publicip.DefaultEndpoint, "HTTP endpoint that responds with the public ip of the caller.")
+	listCmd.PersistentFlags().StringVar(&stunServer, "stun-server", "", "STUN server to discover the public ip with instead of the http endpoint(e.g. "+publicip.DefaultSTUNServer+").")
+	listCmd.PersistentFlags().StringVar(&listMethod, "method", string(list.MethodICMP), "Host discovery method. Supported values include icmp and arp(linux only, requires root).")
+	listCmd.PersistentFlags().BoolVar(&listMDNS, "mdns", true, "Resolve hostnames and advertised services using multicast dns.")
+	listCmd.PersistentFlags().BoolVar(&listSSDP, "ssdp", true, "Identify UPnP devices using ssdp.")
+	listCmd.PersistentFlags().BoolVar(&listNetBIOS, "netbios", true, "Resolve hostnames and workgroups using netbios node status requests.")
+	listCmd.PersistentFlags().BoolVar(&listLLMNR, "llmnr", true, "Resolve hostnames using link-local multicast name resolution.")
+	listCmd.PersistentFlags().BoolVar(&listOS, "os", false, "Guess the operating system of each device from the ttl of its echo replies and by connecting to a few common tcp ports on it. Classification rules that match on ports or os need it.")
+	listCmd.PersistentFlags().BoolVar(&listIPv6, "ipv6", true, "Discover the ipv6 addresses of devices using an all-nodes echo request and the neighbor table.")
+	listCmd.PersistentFlags().IntVarP(&listCount, "count", "c", 1, "Number of echo requests to send to each device to measure its round-trip time and loss.")
+	listCmd.PersistentFlags().BoolVar(&listInventory, "inventory", true, "Record the devices found in the inventory(see nw inventory).")
+	listCmd.Flags().StringSliceVar(&listTags, "tag", nil, "Only list devices labeled with any of the tags(see devices.yaml in the networker config directory).")
+	listWatchCmd.Flags().DurationVar(&watchInterval, "interval", time.Minute, "How often to discover devices.")
+	listWatchCmd.Flags().StringVar(&watchLogFile, "log-file", "", "File to append events to as ndjson.")
+	listWatchCmd.Flags().StringVar(&watchExec, "exec", "", "Shell command to run for each event. The event is written to its stdin as json and exported as NW_EVENT_* environment variables.")
+	listWatchCmd.Flags().StringVar(&watchWebhook, "webhook", "", "URL to post each event to as json.")
+	listCmd.AddCommand(listWatchCmd)
 	Root.AddCommand(listCmd)
 }
 
@@ -37,24 +91,113 @@ var listCmd = &cobra.Command{
 # List devices on network(short-hand) and output as yaml:
 
 	nw ls -o yaml
+
+# List devices on a particular subnet:
+
+	nw ls --cidr 10.0.4.0/22
+
+# List devices on a particular interface:
+
+	nw ls --interface eth0
+
+# List devices on every network the host is attached to:
+
+	nw ls --all-interfaces
+
+# List devices without looking up the public ip of the current device(e.g. on air-gapped networks):
+
+	nw ls --no-public-ip
+
+# List devices and discover the public ip of the current device using a STUN server:
+
+	nw ls --stun-server stun.l.google.com:19302
+
+# List devices on the local segment using an arp sweep instead of pinging each address:
+
+	sudo nw ls --method arp
+
+# List devices without querying multicast dns responders for hostnames and services:
+
+	nw ls --mdns=false
+
+# List devices without searching for UPnP devices(e.g. smart tvs, routers and media servers):
+
+	nw ls --ssdp=false
+
+# List devices without falling back to netbios or llmnr for hostnames of windows machines:
+
+	nw ls --netbios=false --llmnr=false
+
+# List devices and probe them to guess their operating system and which common ports are open:
+
+	nw ls --os
+
+# List devices without discovering their ipv6 addresses:
+
+	nw ls --ipv6=false
+
+# List devices with their loss and min/avg/max/stddev round-trip time measured over 10 echo requests:
+
+	nw ls --count 10
+
+# List devices without recording them in the inventory:
+
+	nw ls --inventory=false
+
+# Watch for devices joining or leaving the network(see nw ls watch --help):
+
+	nw ls watch
+
+# Devices are classified using the rules in classify.yaml in the networker config directory(e.g. ~/.config/networker
+# on linux) if it exists. Rules are evaluated in order and the first one a device matches sets its kind:
+#
+#	rules:
+#	  - kind: printer
+#	    ports: [9100]
+#	    vendors: [brother]
+#
+# Each rule can match on ports, services, upnp, vendors, hostnames(glob patterns) and os.
+# Ports and os are only known when devices are probed with --os so rules that match on them need it.
 `,
 	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
+		if listCount < 1 {
+			usage.Fatalf(cmd, "count must be at least 1")
+		}
+		if err := classify.Load(); err != nil {
+			usage.Fatalf(cmd, "failed to load classification rules: %s", err)
+		}
+		if err := oui.Load(); err != nil {
+			usage.Fatalf(cmd, "failed to load vendor database: %s", err)
+		}
+
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
 		spinner.Start()
 
-		devices, err := list.Devices(ctx)
+		devices, err := list.Devices(ctx, listOptions())
 		if err != nil {
 			usage.Fatalf(cmd, "failed to list devices: %s", err)
 		}
 
 		spinner.Stop()
 
+		labelDevices(loadLabels(cmd), devices)
+
+		if listInventory {
+			if err := recordDevices(devices); err != nil {
+				usage.Fatalf(cmd, "failed to update inventory: %s", err)
+			}
+		}
+
 		devices = slices.DeleteFunc(devices,
 			func(d list.Device) bool {
-				return d.Hostname == "N/A" && d.RemoteIP == nil
+				if len(listTags) > 0 && !labels.HasAnyTag(d.Tags, listTags) {
+					return true
+				}
+				// Devices without a hostname are kept as long as they were found on the network.
+				return !d.Present() && d.Label == "" && d.Kind != list.DeviceKindCurrent
 			},
 		)
 
@@ -64,3 +207,142 @@ var listCmd = &cobra.Command{
 		}
 	},
 }
+
+var listWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch for devices joining, leaving, changing ip or changing hostname.",
+	Example: `
+# Events are written to stdout as ndjson. Devices that are on the network when watching starts
+# only emit joined events if they've never been recorded in the inventory, which marks them as new.
+
+# Watch for devices joining or leaving the network:
+
+	nw ls watch
+
+# Discover devices every 5 minutes:
+
+	nw ls watch --interval 5m
+
+# Append events to a log file:
+
+	nw ls watch --log-file /var/log/networker-events.log
+
+# Run a command for each event:
+
+	nw ls watch --exec 'notify-send "$NW_EVENT_TYPE" "$NW_EVENT_MAC $NW_EVENT_IP"'
+
+# Post each event to a webhook:
+
+	nw ls watch --webhook https://hooks.example.com/networker
+
+# Watch for devices on a particular subnet using an arp sweep:
+
+	sudo nw ls watch --cidr 192.168.1.0/24 --method arp
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		if watchInterval <= 0 {
+			usage.Fatalf(cmd, "interval must be positive")
+		}
+		if err := classify.Load(); err != nil {
+			usage.Fatalf(cmd, "failed to load classification rules: %s", err)
+		}
+		if err := oui.Load(); err != nil {
+			usage.Fatalf(cmd, "failed to load vendor database: %s", err)
+		}
+
+		sinks := []watch.Sink{watch.NDJSON(os.Stdout)}
+		if watchLogFile != "" {
+			f, err := os.OpenFile(watchLogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				usage.Fatalf(cmd, "failed to open log file: %s", err)
+			}
+			defer f.Close()
+			sinks = append(sinks, watch.NDJSON(f))
+		}
+		if watchExec != "" {
+			sinks = append(sinks, watch.Exec(watchExec))
+		}
+		if watchWebhook != "" {
+			sinks = append(sinks, watch.Webhook(watchWebhook))
+		}
+
+		known := make(map[string]bool)
+		if inv, err := inventory.Load(); err == nil {
+			for id := range inv.Devices {
+				known[id] = true
+			}
+		}
+
+		var (
+			opts = listOptions()
+			ls   = loadLabels(cmd)
+		)
+
+		w := &watch.Watcher{
+			Interval: watchInterval,
+			Sinks:    sinks,
+			Known:    known,
+			Discover: func(ctx context.Context) ([]list.Device, error) {
+				devices, err := list.Devices(ctx, opts)
+				if err != nil {
+					return nil, err
+				}
+				labelDevices(ls, devices)
+				if listInventory {
+					if err := recordDevices(devices); err != nil {
+						return nil, fmt.Errorf("failed to update inventory: %w", err)
+					}
+				}
+				return devices, nil
+			},
+			OnError: func(err error) {
+				fmt.Fprintln(os.Stderr, err)
+			},
+		}
+
+		if err := w.Run(ctx); err != nil {
+			usage.Fatalf(cmd, "failed to watch devices: %s", err)
+		}
+	},
+}
+
+func listOptions() list.Options {
+	method := ping.Detect()
+	if method == ping.MethodTCP && list.Method(listMethod) != list.MethodARP {
+		fmt.Fprintln(os.Stderr, "icmp is not permitted for this user, pinging devices with tcp instead")
+	}
+
+	return list.Options{
+		CIDR:          listCIDR,
+		Interface:     listInterface,
+		AllInterfaces: listAllInterfaces,
+		PublicIP:      publicIPResolver(),
+		Method:        list.Method(listMethod),
+		MDNS:          listMDNS,
+		SSDP:          listSSDP,
+		NetBIOS:       listNetBIOS,
+		LLMNR:         listLLMNR,
+		OS:            listOS,
+		IPv6:          listIPv6,
+		Count:         listCount,
+		Ping:          method,
+		OnWarning: func(err error) {
+			fmt.Fprintln(os.Stderr, err)
+		},
+	}
+}
+
+func publicIPResolver() publicip.Resolver {
+	switch {
+	case noPublicIP:
+		return nil
+	case stunServer != "":
+		return publicip.STUN(stunServer)
+	default:
+		return publicip.HTTP(publicIPEndpoint)
+	}
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fuskovic/networker/v3/internal/encoder"
+	"github.com/fuskovic/networker/v3/internal/mdns"
+	"github.com/fuskovic/networker/v3/internal/spinner"
+	"github.com/fuskovic/networker/v3/internal/usage"
+)
+
+var (
+	mdnsTimeout   time.Duration
+	mdnsInterface string
+)
+
+func init() {
+	mdnsBrowseCmd.Flags().DurationVarP(&mdnsTimeout, "timeout", "t", 3*time.Second, "How long to wait for responses.")
+	mdnsBrowseCmd.Flags().StringVarP(&mdnsInterface, "interface", "i", "", "Interface to send queries on(defaults to the system default multicast interface).")
+	mdnsCmd.AddCommand(mdnsBrowseCmd)
+	Root.AddCommand(mdnsCmd)
+}
+
+var mdnsCmd = &cobra.Command{
+	Use:   "mdns",
+	Short: "Discover services advertised using multicast dns.",
+	Example: `
+# Browse services advertised on the local network:
+
+	nw mdns browse
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Usage()
+	},
+}
+
+var mdnsBrowseCmd = &cobra.Command{
+	Use:     "browse",
+	Aliases: []string{"b"},
+	Short:   "Browse services advertised on the local network.",
+	Example: `
+# Browse services advertised on the local network:
+
+	nw mdns browse
+
+# Browse services advertised on the local network and output as json:
+
+	nw mdns browse -o json
+
+# Browse services advertised on a particular interface and wait longer for responses:
+
+	nw mdns browse --interface eth0 --timeout 10s
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var ifaces []net.Interface
+		if mdnsInterface != "" {
+			iface, err := net.InterfaceByName(mdnsInterface)
+			if err != nil {
+				usage.Fatalf(cmd, "failed to find interface %q: %s", mdnsInterface, err)
+			}
+			ifaces = append(ifaces, *iface)
+		}
+
+		spinner.Start()
+
+		services, err := mdns.Browse(ctx, mdnsTimeout, ifaces...)
+		if err != nil {
+			usage.Fatalf(cmd, "failed to browse services: %s", err)
+		}
+
+		spinner.Stop()
+
+		enc := encoder.New[mdns.Service](os.Stdout, output)
+		if err := enc.Encode(services...); err != nil {
+			usage.Fatalf(cmd, "failed to encode services: %s", err)
+		}
+	},
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fuskovic/networker/v3/internal/encoder"
+	"github.com/fuskovic/networker/v3/internal/mtr"
+	"github.com/fuskovic/networker/v3/internal/resolve"
+	"github.com/fuskovic/networker/v3/internal/spinner"
+	"github.com/fuskovic/networker/v3/internal/traceroute"
+	"github.com/fuskovic/networker/v3/internal/usage"
+)
+
+var (
+	mtrReport    bool
+	mtrCycles    int
+	mtrInterval  time.Duration
+	mtrProtocol  string
+	mtrMaxHops   int
+	mtrTimeout   time.Duration
+	mtrPort      int
+	mtrIPv4      bool
+	mtrIPv6      bool
+	mtrHostnames bool
+	mtrASN       bool
+)
+
+// defaultReportCycles is how many cycles are sent in report mode unless --cycles is set.
+const defaultReportCycles = 10
+
+func init() {
+	mtrCmd.Flags().BoolVarP(&mtrReport, "report", "r", false, "Probe for a number of cycles and then output a report instead of a live table.")
+	mtrCmd.Flags().IntVarP(&mtrCycles, "cycles", "c", 0, fmt.Sprintf("Number of times to probe every hop(defaults to %d in report mode and until interrupted otherwise).", defaultReportCycles))
+	mtrCmd.Flags().DurationVar(&mtrInterval, "interval", mtr.DefaultInterval, "Time between cycles.")
+	mtrCmd.Flags().StringVarP(&mtrProtocol, "protocol", "P", string(traceroute.ProtocolICMP), "Kind of probes to send. Supported values include icmp, udp and tcp.")
+	mtrCmd.Flags().IntVarP(&mtrMaxHops, "max-hops", "m", traceroute.DefaultMaxHops, "Largest number of hops to probe.")
+	mtrCmd.Flags().DurationVar(&mtrTimeout, "timeout", traceroute.DefaultTimeout, "How long to wait for the reply to each probe.")
+	mtrCmd.Flags().IntVarP(&mtrPort, "port", "p", 0, "Destination port of udp and tcp probes(defaults to 33434 for udp and 80 for tcp).")
+	mtrCmd.Flags().BoolVarP(&mtrIPv4, "ipv4", "4", false, "Only monitor the path to ipv4 addresses.")
+	mtrCmd.Flags().BoolVarP(&mtrIPv6, "ipv6", "6", false, "Only monitor the path to ipv6 addresses.")
+	mtrCmd.Flags().BoolVar(&mtrHostnames, "hostnames", true, "Resolve the hostname of each hop.")
+	mtrCmd.Flags().BoolVar(&mtrASN, "asn", true, "Resolve the autonomous system number and internet service provider of each public hop.")
+	Root.AddCommand(mtrCmd)
+}
+
+var mtrCmd = &cobra.Command{
+	Use:   "mtr",
+	Short: "Continuously probe the path to a host and show the loss, latency and jitter of every hop.",
+	Example: `
+# Show a live table of every hop on the path to a host until interrupted(requires root):
+
+	sudo nw mtr example.com
+
+# Probe every hop 100 times and output a report as json to attach to a ticket:
+
+	sudo nw mtr example.com --report --cycles 100 -o json
+
+# Probe every hop with tcp handshakes on port 443 every 5 seconds:
+
+	sudo nw mtr example.com -P tcp -p 443 --interval 5s
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if mtrIPv4 && mtrIPv6 {
+			usage.Fatalf(cmd, "--ipv4 and --ipv6 can't be used together")
+		}
+		if mtrCycles < 0 {
+			usage.Fatalf(cmd, "cycles can't be negative")
+		}
+		if !mtrReport && (output == "json" || output == "yaml") {
+			usage.Fatalf(cmd, "json and yaml output require --report")
+		}
+
+		protocol := traceroute.Protocol(mtrProtocol)
+		switch protocol {
+		case traceroute.ProtocolUDP, traceroute.ProtocolICMP, traceroute.ProtocolTCP:
+		default:
+			usage.Fatalf(cmd, "unsupported protocol %q", mtrProtocol)
+		}
+
+		network := "ip"
+		switch {
+		case mtrIPv4:
+			network = "ip4"
+		case mtrIPv6:
+			network = "ip6"
+		}
+
+		ip, err := resolve.AddrByHostAndNetwork(args[0], network)
+		if err != nil {
+			usage.Fatalf(cmd, "failed to resolve %s: %s", args[0], err)
+		}
+
+		cycles := mtrCycles
+		if mtrReport && cycles == 0 {
+			cycles = defaultReportCycles
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		opts := mtr.Options{
+			Trace: traceroute.Options{
+				Protocol:  protocol,
+				MaxHops:   mtrMaxHops,
+				Timeout:   mtrTimeout,
+				Port:      mtrPort,
+				Hostnames: mtrHostnames,
+				ASN:       mtrASN,
+			},
+			Interval: mtrInterval,
+			Cycles:   cycles,
+		}
+
+		enc := encoder.New[mtr.Hop](os.Stdout, output)
+
+		if !mtrReport {
+			// The live table is redrawn in place after every cycle.
+			_, err := mtr.Run(ctx, ip, opts, func(cycle int, hops []mtr.Hop) {
+				fmt.Print("\033[H\033[2J")
+				fmt.Printf("%s(%s) cycle %d\n\n", args[0], ip, cycle)
+				if err := enc.Encode(hops...); err != nil {
+					usage.Fatalf(cmd, "failed to encode hops: %s", err)
+				}
+			})
+			if err != nil {
+				usage.Fatalf(cmd, "failed to monitor %s: %s", args[0], err)
+			}
+			return
+		}
+
+		spinner.Start()
+
+		hops, err := mtr.Run(ctx, ip, opts, nil)
+		if err != nil {
+			usage.Fatalf(cmd, "failed to monitor %s: %s", args[0], err)
+		}
+
+		spinner.Stop()
+
+		if err := enc.Encode(hops...); err != nil {
+			usage.Fatalf(cmd, "failed to encode hops: %s", err)
+		}
+	},
+}
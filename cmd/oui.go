@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"net"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fuskovic/networker/v3/internal/encoder"
+	"github.com/fuskovic/networker/v3/internal/oui"
+	"github.com/fuskovic/networker/v3/internal/usage"
+)
+
+var ouiFile string
+
+func init() {
+	ouiUpdateCmd.Flags().StringVarP(&ouiFile, "file", "f", "", "Path to an IEEE registry csv.")
+	_ = ouiUpdateCmd.MarkFlagRequired("file")
+	ouiCmd.AddCommand(ouiLookupCmd)
+	ouiCmd.AddCommand(ouiUpdateCmd)
+	Root.AddCommand(ouiCmd)
+}
+
+var ouiCmd = &cobra.Command{
+	Use:   "oui",
+	Short: "Identify hardware vendors by their organizationally unique identifier.",
+	Example: `
+# Lookup the vendor of a hardware address:
+
+	nw oui lookup b8:27:eb:12:34:56
+
+# Refresh the vendor database from a downloaded IEEE registry(https://standards-oui.ieee.org/oui/oui.csv):
+
+	nw oui update --file oui.csv
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Usage()
+	},
+}
+
+var ouiLookupCmd = &cobra.Command{
+	Use:     "lookup",
+	Aliases: []string{"lu"},
+	Short:   "Lookup the vendor of a hardware address.",
+	Example: `
+# Lookup the vendor of a hardware address:
+
+	nw oui lookup b8:27:eb:12:34:56
+
+# Lookup the vendor of a hardware address and output as json:
+
+	nw oui lookup b8:27:eb:12:34:56 -o json
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		mac, err := net.ParseMAC(args[0])
+		if err != nil {
+			usage.Fatalf(cmd, "%q is not a valid hardware address", args[0])
+		}
+
+		if err := oui.Load(); err != nil {
+			usage.Fatalf(cmd, "failed to load vendor database: %s", err)
+		}
+
+		vendor := oui.Vendor(mac)
+		if vendor == "" {
+			vendor = "N/A"
+		}
+
+		enc := encoder.New[oui.Record](os.Stdout, output)
+		if err := enc.Encode(oui.Record{MAC: mac.String(), Vendor: vendor}); err != nil {
+			usage.Fatalf(cmd, "failed to encode vendor: %s", err)
+		}
+	},
+}
+
+var ouiUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Refresh the vendor database from a downloaded IEEE registry.",
+	Example: `
+# Refresh the vendor database from a downloaded IEEE registry(https://standards-oui.ieee.org/oui/oui.csv):
+
+	nw oui update --file oui.csv
+
+# MA-M and MA-S registries can be combined with the MA-L registry before updating:
+
+	tail -q -n +2 mam.csv oui36.csv >> oui.csv && nw oui update --file oui.csv
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		n, err := oui.Update(ouiFile)
+		if err != nil {
+			usage.Fatalf(cmd, "failed to update vendor database: %s", err)
+		}
+		cmd.Printf("installed %d assignments\n", n)
+	},
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fuskovic/networker/v3/internal/encoder"
+	"github.com/fuskovic/networker/v3/internal/ping"
+	"github.com/fuskovic/networker/v3/internal/resolve"
+	"github.com/fuskovic/networker/v3/internal/usage"
+)
+
+var (
+	pingCount    int
+	pingInterval time.Duration
+	pingTimeout  time.Duration
+	pingSize     int
+	pingTTL      int
+	pingIPv4     bool
+	pingIPv6     bool
+	pingTCPPort  int
+)
+
+func init() {
+	pingCmd.Flags().IntVarP(&pingCount, "count", "c", 0, "Number of probes to send(defaults to sending them until interrupted).")
+	pingCmd.Flags().DurationVar(&pingInterval, "interval", time.Second, "Time between probes.")
+	pingCmd.Flags().DurationVar(&pingTimeout, "timeout", time.Second, "How long to wait for each reply.")
+	pingCmd.Flags().IntVarP(&pingSize, "size", "s", 56, "Number of data bytes to send in each echo request.")
+	pingCmd.Flags().IntVar(&pingTTL, "ttl", 0, "Time-to-live(or hop limit) of each echo request(defaults to the system default).")
+	pingCmd.Flags().BoolVarP(&pingIPv4, "ipv4", "4", false, "Only ping ipv4 addresses.")
+	pingCmd.Flags().BoolVarP(&pingIPv6, "ipv6", "6", false, "Only ping ipv6 addresses.")
+	pingCmd.Flags().IntVar(&pingTCPPort, "tcp", 0, "Ping by connecting to a tcp port instead of sending icmp echo requests.")
+	Root.AddCommand(pingCmd)
+}
+
+var pingCmd = &cobra.Command{
+	Use:   "ping",
+	Short: "Ping a host and summarize its round-trip time and loss.",
+	Example: `
+# Ping a host until interrupted:
+
+	nw ping example.com
+
+# Send 5 echo requests and output the summary as json:
+
+	nw ping example.com -c 5 -o json
+
+# Send an echo request every 200ms:
+
+	nw ping 192.168.1.1 --interval 200ms
+
+# Send 1400 data bytes in each echo request:
+
+	nw ping example.com -s 1400
+
+# Send echo requests that expire after 3 hops:
+
+	nw ping example.com --ttl 3
+
+# Ping the ipv6 address of a host:
+
+	nw ping example.com -6
+
+# Ping a host that blocks icmp by connecting to one of its tcp ports:
+
+	nw ping example.com --tcp 443
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if pingIPv4 && pingIPv6 {
+			usage.Fatalf(cmd, "--ipv4 and --ipv6 can't be used together")
+		}
+		if pingCount < 0 {
+			usage.Fatalf(cmd, "count can't be negative")
+		}
+		if pingInterval <= 0 {
+			usage.Fatalf(cmd, "interval must be positive")
+		}
+		if pingTimeout <= 0 {
+			usage.Fatalf(cmd, "timeout must be positive")
+		}
+		// 65507 is the largest payload that fits in an ipv4 packet after the ip and icmp headers.
+		if pingSize < 0 || pingSize > 65507 {
+			usage.Fatalf(cmd, "size must be between 0 and 65507")
+		}
+		if cmd.Flags().Changed("ttl") && (pingTTL < 1 || pingTTL > 255) {
+			usage.Fatalf(cmd, "ttl must be between 1 and 255")
+		}
+		if pingTCPPort != 0 && (cmd.Flags().Changed("size") || cmd.Flags().Changed("ttl")) {
+			usage.Fatalf(cmd, "--size and --ttl are only supported by icmp pings")
+		}
+
+		network := "ip"
+		switch {
+		case pingIPv4:
+			network = "ip4"
+		case pingIPv6:
+			network = "ip6"
+		}
+
+		ip, err := resolve.AddrByHostAndNetwork(args[0], network)
+		if err != nil {
+			usage.Fatalf(cmd, "failed to resolve %s: %s", args[0], err)
+		}
+
+		method := ping.MethodTCP
+		if pingTCPPort == 0 {
+			if method = ping.Detect(); method == ping.MethodTCP {
+				fmt.Fprintln(os.Stderr, "icmp is not permitted for this user, pinging with tcp instead")
+			}
+		}
+
+		pinger := ping.NewPinger(method, 1)
+		pinger.Count = pingCount
+		pinger.Interval = pingInterval
+		pinger.Timeout = pingTimeout
+		pinger.Size = pingSize
+		pinger.TTL = pingTTL
+		if pingTCPPort != 0 {
+			pinger.Ports = []int{pingTCPPort}
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		var (
+			summary = ping.Summary{Host: args[0], IP: ip}
+			// Replies are printed as they arrive unless the summary is encoded as json or yaml.
+			isTable = output != "json" && output != "yaml"
+		)
+
+		summary.Statistics = pinger.Stream(ctx, ip, func(r ping.Result) {
+			summary.Results = append(summary.Results, r)
+			if !isTable {
+				return
+			}
+			switch {
+			case r.Error != "":
+				fmt.Printf("no reply from %s: seq=%d: %s\n", ip, r.Seq, r.Error)
+			case r.TTL > 0:
+				fmt.Printf("reply from %s: seq=%d ttl=%d time=%s\n", ip, r.Seq, r.TTL, r.RTT)
+			default:
+				fmt.Printf("reply from %s: seq=%d time=%s\n", ip, r.Seq, r.RTT)
+			}
+		})
+
+		if isTable {
+			fmt.Println()
+		}
+
+		enc := encoder.New[ping.Summary](os.Stdout, output)
+		if err := enc.Encode(summary); err != nil {
+			usage.Fatalf(cmd, "failed to encode summary: %s", err)
+		}
+	},
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fuskovic/networker/v3/internal/encoder"
+	"github.com/fuskovic/networker/v3/internal/pmtu"
+	"github.com/fuskovic/networker/v3/internal/resolve"
+	"github.com/fuskovic/networker/v3/internal/spinner"
+	"github.com/fuskovic/networker/v3/internal/usage"
+)
+
+var (
+	pmtuProtocol string
+	pmtuMax      int
+	pmtuTimeout  time.Duration
+	pmtuRetries  int
+	pmtuPort     int
+	pmtuIPv4     bool
+	pmtuIPv6     bool
+)
+
+func init() {
+	pmtuCmd.Flags().StringVarP(&pmtuProtocol, "protocol", "P", string(pmtu.ProtocolICMP), "Kind of probes to send. Supported values include icmp and udp.")
+	pmtuCmd.Flags().IntVar(&pmtuMax, "max", 0, "Largest packet size to probe. Defaults to and is capped at the mtu of the outgoing interface since larger packets can't be sent without fragmenting.")
+	pmtuCmd.Flags().DurationVar(&pmtuTimeout, "timeout", pmtu.DefaultTimeout, "How long to wait for the reply to each probe.")
+	pmtuCmd.Flags().IntVar(&pmtuRetries, "retries", pmtu.DefaultRetries, "How many more times to send a probe that went unanswered before it's considered too big.")
+	pmtuCmd.Flags().IntVarP(&pmtuPort, "port", "p", pmtu.DefaultUDPPort, "Destination port of udp probes.")
+	pmtuCmd.Flags().BoolVarP(&pmtuIPv4, "ipv4", "4", false, "Only discover the path mtu of ipv4 addresses.")
+	pmtuCmd.Flags().BoolVarP(&pmtuIPv6, "ipv6", "6", false, "Only discover the path mtu of ipv6 addresses.")
+	Root.AddCommand(pmtuCmd)
+}
+
+var pmtuCmd = &cobra.Command{
+	Use:   "pmtu",
+	Short: "Discover the largest packet that reaches a host without being fragmented.",
+	Example: `
+# Discover the path mtu to a host with icmp echo requests(requires root):
+
+	sudo nw pmtu example.com
+
+# Discover the path mtu through a vpn with udp probes and output as json:
+
+	sudo nw pmtu 10.8.0.1 -P udp -o json
+
+# Discover the path mtu to the ipv6 address of a host:
+
+	sudo nw pmtu example.com -6
+
+# Only probe packet sizes up to 1400 bytes, e.g. to check whether a host is reachable through a tunnel with that mtu:
+
+	sudo nw pmtu 192.168.1.10 --max 1400
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if pmtuIPv4 && pmtuIPv6 {
+			usage.Fatalf(cmd, "--ipv4 and --ipv6 can't be used together")
+		}
+
+		protocol := pmtu.Protocol(pmtuProtocol)
+		switch protocol {
+		case pmtu.ProtocolICMP, pmtu.ProtocolUDP:
+		default:
+			usage.Fatalf(cmd, "unsupported protocol %q", pmtuProtocol)
+		}
+
+		network := "ip"
+		switch {
+		case pmtuIPv4:
+			network = "ip4"
+		case pmtuIPv6:
+			network = "ip6"
+		}
+
+		ip, err := resolve.AddrByHostAndNetwork(args[0], network)
+		if err != nil {
+			usage.Fatalf(cmd, "failed to resolve %s: %s", args[0], err)
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		spinner.Start()
+
+		result, err := pmtu.Discover(ctx, ip, pmtu.Options{
+			Protocol: protocol,
+			Max:      pmtuMax,
+			Timeout:  pmtuTimeout,
+			Retries:  pmtuRetries,
+			Port:     pmtuPort,
+		})
+		if err != nil {
+			usage.Fatalf(cmd, "failed to discover path mtu to %s: %s", args[0], err)
+		}
+		result.Host = args[0]
+
+		spinner.Stop()
+
+		enc := encoder.New[pmtu.Result](os.Stdout, output)
+		if err := enc.Encode(*result); err != nil {
+			usage.Fatalf(cmd, "failed to encode result: %s", err)
+		}
+	},
+}
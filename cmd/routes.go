@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fuskovic/networker/v3/internal/encoder"
+	"github.com/fuskovic/networker/v3/internal/resolve"
+	"github.com/fuskovic/networker/v3/internal/route"
+	"github.com/fuskovic/networker/v3/internal/usage"
+)
+
+var (
+	routesIPv4  bool
+	routesIPv6  bool
+	routesLocal bool
+)
+
+func init() {
+	routesCmd.PersistentFlags().BoolVarP(&routesIPv4, "ipv4", "4", false, "Only use ipv4 routes.")
+	routesCmd.PersistentFlags().BoolVarP(&routesIPv6, "ipv6", "6", false, "Only use ipv6 routes.")
+	routesCmd.Flags().BoolVar(&routesLocal, "local", false, "Include the routes of the local table.")
+	routesCmd.AddCommand(routesGetCmd)
+	Root.AddCommand(routesCmd)
+}
+
+var routesCmd = &cobra.Command{
+	Use:     "routes",
+	Aliases: []string{"route", "r"},
+	Short:   "List the ipv4 and ipv6 routing tables.",
+	Example: `
+# List the routes of every routing table:
+
+	nw routes
+
+# List ipv4 routes and output as json:
+
+	nw routes -4 -o json
+
+# List routes including those of the local table:
+
+	nw routes --local
+
+# Show the route packets to a host are sent over:
+
+	nw routes get example.com
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if routesIPv4 && routesIPv6 {
+			usage.Fatalf(cmd, "--ipv4 and --ipv6 can't be used together")
+		}
+
+		var network string
+		switch {
+		case routesIPv4:
+			network = "ip4"
+		case routesIPv6:
+			network = "ip6"
+		}
+
+		routes, err := route.List(route.Options{Network: network, Local: routesLocal})
+		if err != nil {
+			usage.Fatalf(cmd, "failed to list routes: %s", err)
+		}
+
+		enc := encoder.New[route.Route](os.Stdout, output)
+		if err := enc.Encode(routes...); err != nil {
+			usage.Fatalf(cmd, "failed to encode routes: %s", err)
+		}
+	},
+}
+
+var routesGetCmd = &cobra.Command{
+	Use:     "get",
+	Aliases: []string{"g"},
+	Short:   "Show the route, interface and source address packets to a host are sent with.",
+	Example: `
+# Show the route packets to an ip address are sent over:
+
+	nw routes get 10.8.0.1
+
+# Show the route packets to the ipv6 address of a host are sent over and output as json:
+
+	nw routes get example.com -6 -o json
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if routesIPv4 && routesIPv6 {
+			usage.Fatalf(cmd, "--ipv4 and --ipv6 can't be used together")
+		}
+
+		network := "ip"
+		switch {
+		case routesIPv4:
+			network = "ip4"
+		case routesIPv6:
+			network = "ip6"
+		}
+
+		ip, err := resolve.AddrByHostAndNetwork(args[0], network)
+		if err != nil {
+			usage.Fatalf(cmd, "failed to resolve %s: %s", args[0], err)
+		}
+
+		r, err := route.Get(ip)
+		if err != nil {
+			usage.Fatalf(cmd, "failed to get route to %s: %s", args[0], err)
+		}
+
+		enc := encoder.New[route.Route](os.Stdout, output)
+		if err := enc.Encode(*r); err != nil {
+			usage.Fatalf(cmd, "failed to encode route: %s", err)
+		}
+	},
+}
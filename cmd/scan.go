@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/fuskovic/networker/v3/internal/encoder"
+	"github.com/fuskovic/networker/v3/internal/labels"
 	"github.com/fuskovic/networker/v3/internal/list"
 	"github.com/fuskovic/networker/v3/internal/resolve"
 	"github.com/fuskovic/networker/v3/internal/scanner"
@@ -16,10 +17,14 @@ import (
 	"github.com/fuskovic/networker/v3/internal/usage"
 )
 
-var scanAllPorts bool
+var (
+	scanAllPorts bool
+	scanTags     []string
+)
 
 func init() {
 	scanCmd.Flags().BoolVar(&scanAllPorts, "all-ports", false, "Scan all ports(scans first 1024 if not enabled).")
+	scanCmd.Flags().StringSliceVar(&scanTags, "tag", nil, "Only scan devices on the network labeled with any of the tags(see devices.yaml in the networker config directory).")
 	Root.AddCommand(scanCmd)
 }
 
@@ -92,22 +97,40 @@ var scanCmd = &cobra.Command{
 
 		nw s localhost -o yaml --all-ports
 
+# Scan well-known ports(first 1024) of devices on network labeled with a tag:
+
+		nw s --tag critical
+
 `,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
-		var hosts []string
+		var (
+			hosts []string
+			ls    = loadLabels(cmd)
+			// macs are the hardware addresses of the devices on the network keyed by their ip address.
+			macs = make(map[string]string)
+		)
+
 		if len(args) == 0 {
-			devices, err := list.Devices(ctx)
+			devices, err := list.Devices(ctx, list.Options{})
 			if err != nil {
 				usage.Fatalf(cmd, "failed to list network devices: %s", err)
 			}
+			labelDevices(ls, devices)
 			for i := range devices {
+				if len(scanTags) > 0 && !labels.HasAnyTag(devices[i].Tags, scanTags) {
+					continue
+				}
 				hosts = append(hosts, devices[i].LocalIP.String())
+				macs[devices[i].LocalIP.String()] = devices[i].MAC
 			}
 		} else {
+			if len(scanTags) > 0 {
+				usage.Fatalf(cmd, "--tag can only be used when scanning devices on the network")
+			}
 			ip := net.ParseIP(args[0])
 			if ip == nil {
 				record, err := resolve.AddrByHostName(args[0])
@@ -128,9 +151,16 @@ var scanCmd = &cobra.Command{
 
 		spinner.Stop()
 
+		for i := range scans {
+			if l := ls.Lookup(macs[scans[i].IP], scans[i].IP); l != nil {
+				scans[i].Label = l.Name
+				scans[i].Tags = l.Tags
+			}
+		}
+
 		scans = slices.DeleteFunc(scans,
 			func(s scanner.Scan) bool {
-				return s.Host == "N/A" && len(s.Ports) == 0
+				return s.Host == "N/A" && s.Label == "" && len(s.Ports) == 0
 			},
 		)
 
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fuskovic/networker/v3/internal/encoder"
+	"github.com/fuskovic/networker/v3/internal/socket"
+	"github.com/fuskovic/networker/v3/internal/usage"
+)
+
+var (
+	socketsListening bool
+	socketsPort      int
+	socketsTCP       bool
+	socketsUDP       bool
+	socketsUnix      bool
+)
+
+func init() {
+	socketsCmd.Flags().BoolVarP(&socketsListening, "listening", "l", false, "Only list sockets that are waiting for connections or datagrams.")
+	socketsCmd.Flags().IntVarP(&socketsPort, "port", "p", 0, "Only list sockets with this local or remote port.")
+	socketsCmd.Flags().BoolVarP(&socketsTCP, "tcp", "t", false, "List tcp sockets.")
+	socketsCmd.Flags().BoolVarP(&socketsUDP, "udp", "u", false, "List udp sockets.")
+	socketsCmd.Flags().BoolVarP(&socketsUnix, "unix", "x", false, "List unix sockets.")
+	Root.AddCommand(socketsCmd)
+}
+
+var socketsCmd = &cobra.Command{
+	Use:     "sockets",
+	Aliases: []string{"ss"},
+	Short:   "List local sockets and the processes that own them.",
+	Example: `
+# List every socket(run as root to see the processes of other users):
+
+	sudo nw sockets
+
+# List listening tcp and udp sockets:
+
+	nw ss -l -t -u
+
+# List sockets connected to or listening on port 443 and output as json:
+
+	nw ss --port 443 -o json
+
+# List listening unix sockets:
+
+	nw ss -l -x
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if socketsPort < 0 || socketsPort > 65535 {
+			usage.Fatalf(cmd, "invalid port %d", socketsPort)
+		}
+		if socketsPort != 0 && socketsUnix {
+			usage.Fatalf(cmd, "--port can't be used with --unix since unix sockets don't have ports")
+		}
+
+		// Sockets of every protocol are listed unless some are picked.
+		var protocols []string
+		if socketsTCP {
+			protocols = append(protocols, socket.ProtocolTCP, socket.ProtocolTCP6)
+		}
+		if socketsUDP {
+			protocols = append(protocols, socket.ProtocolUDP, socket.ProtocolUDP6)
+		}
+		if socketsUnix {
+			protocols = append(protocols, socket.ProtocolUnix)
+		}
+
+		sockets, err := socket.List(socket.Options{
+			Protocols: protocols,
+			Listening: socketsListening,
+			Port:      socketsPort,
+		})
+		if err != nil {
+			usage.Fatalf(cmd, "failed to list sockets: %s", err)
+		}
+
+		enc := encoder.New[socket.Socket](os.Stdout, output)
+		if err := enc.Encode(sockets...); err != nil {
+			usage.Fatalf(cmd, "failed to encode sockets: %s", err)
+		}
+	},
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fuskovic/networker/v3/internal/encoder"
+	"github.com/fuskovic/networker/v3/internal/resolve"
+	"github.com/fuskovic/networker/v3/internal/spinner"
+	"github.com/fuskovic/networker/v3/internal/traceroute"
+	"github.com/fuskovic/networker/v3/internal/usage"
+)
+
+var (
+	traceProtocol  string
+	traceMaxHops   int
+	traceQueries   int
+	traceTimeout   time.Duration
+	tracePort      int
+	traceIPv4      bool
+	traceIPv6      bool
+	traceHostnames bool
+	traceASN       bool
+)
+
+func init() {
+	tracerouteCmd.Flags().StringVarP(&traceProtocol, "protocol", "P", string(traceroute.ProtocolUDP), "Kind of probes to send. Supported values include udp, icmp and tcp.")
+	tracerouteCmd.Flags().IntVarP(&traceMaxHops, "max-hops", "m", traceroute.DefaultMaxHops, "Largest number of hops to probe.")
+	tracerouteCmd.Flags().IntVarP(&traceQueries, "queries", "q", traceroute.DefaultQueries, "Number of probes to send to each hop.")
+	tracerouteCmd.Flags().DurationVar(&traceTimeout, "timeout", traceroute.DefaultTimeout, "How long to wait for the reply to each probe.")
+	tracerouteCmd.Flags().IntVarP(&tracePort, "port", "p", 0, "Destination port of udp and tcp probes(defaults to 33434 for udp and 80 for tcp).")
+	tracerouteCmd.Flags().BoolVarP(&traceIPv4, "ipv4", "4", false, "Only trace the path to ipv4 addresses.")
+	tracerouteCmd.Flags().BoolVarP(&traceIPv6, "ipv6", "6", false, "Only trace the path to ipv6 addresses.")
+	tracerouteCmd.Flags().BoolVar(&traceHostnames, "hostnames", true, "Resolve the hostname of each hop.")
+	tracerouteCmd.Flags().BoolVar(&traceASN, "asn", true, "Resolve the autonomous system number and internet service provider of each public hop.")
+	Root.AddCommand(tracerouteCmd)
+}
+
+var tracerouteCmd = &cobra.Command{
+	Use:     "traceroute",
+	Aliases: []string{"trace", "tr"},
+	Short:   "Trace the path to a host and the providers it goes through.",
+	Example: `
+# Trace the path to a host with udp probes(requires root):
+
+	sudo nw traceroute example.com
+
+# Trace the path to a host with icmp echo requests and output as json:
+
+	sudo nw tr example.com -P icmp -o json
+
+# Trace the path to a web server through firewalls that only allow tcp:
+
+	sudo nw tr example.com -P tcp -p 443
+
+# Trace the path to the ipv6 address of a host without resolving hostnames or providers:
+
+	sudo nw tr example.com -6 --hostnames=false --asn=false
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if traceIPv4 && traceIPv6 {
+			usage.Fatalf(cmd, "--ipv4 and --ipv6 can't be used together")
+		}
+
+		protocol := traceroute.Protocol(traceProtocol)
+		switch protocol {
+		case traceroute.ProtocolUDP, traceroute.ProtocolICMP, traceroute.ProtocolTCP:
+		default:
+			usage.Fatalf(cmd, "unsupported protocol %q", traceProtocol)
+		}
+
+		network := "ip"
+		switch {
+		case traceIPv4:
+			network = "ip4"
+		case traceIPv6:
+			network = "ip6"
+		}
+
+		ip, err := resolve.AddrByHostAndNetwork(args[0], network)
+		if err != nil {
+			usage.Fatalf(cmd, "failed to resolve %s: %s", args[0], err)
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		spinner.Start()
+
+		hops, err := traceroute.Trace(ctx, ip, traceroute.Options{
+			Protocol:  protocol,
+			MaxHops:   traceMaxHops,
+			Queries:   traceQueries,
+			Timeout:   traceTimeout,
+			Port:      tracePort,
+			Hostnames: traceHostnames,
+			ASN:       traceASN,
+		})
+		if err != nil {
+			usage.Fatalf(cmd, "failed to trace %s: %s", args[0], err)
+		}
+
+		spinner.Stop()
+
+		enc := encoder.New[traceroute.Hop](os.Stdout, output)
+		if err := enc.Encode(hops...); err != nil {
+			usage.Fatalf(cmd, "failed to encode hops: %s", err)
+		}
+	},
+}
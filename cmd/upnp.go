@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fuskovic/networker/v3/internal/encoder"
+	"github.com/fuskovic/networker/v3/internal/spinner"
+	"github.com/fuskovic/networker/v3/internal/ssdp"
+	"github.com/fuskovic/networker/v3/internal/usage"
+)
+
+var (
+	upnpTimeout   time.Duration
+	upnpInterface string
+)
+
+func init() {
+	upnpDiscoverCmd.Flags().DurationVarP(&upnpTimeout, "timeout", "t", 3*time.Second, "How long to wait for responses.")
+	upnpDiscoverCmd.Flags().StringVarP(&upnpInterface, "interface", "i", "", "Interface to send the search on(defaults to the system default multicast interface).")
+	upnpCmd.AddCommand(upnpDiscoverCmd)
+	Root.AddCommand(upnpCmd)
+}
+
+var upnpCmd = &cobra.Command{
+	Use:   "upnp",
+	Short: "Discover UPnP devices using ssdp.",
+	Example: `
+# Discover UPnP devices on the local network:
+
+	nw upnp discover
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Usage()
+	},
+}
+
+var upnpDiscoverCmd = &cobra.Command{
+	Use:     "discover",
+	Aliases: []string{"d"},
+	Short:   "Discover UPnP devices on the local network.",
+	Example: `
+# Discover UPnP devices on the local network:
+
+	nw upnp discover
+
+# Discover UPnP devices on the local network and output as json:
+
+	nw upnp discover -o json
+
+# Discover UPnP devices on a particular interface and wait longer for responses:
+
+	nw upnp discover --interface eth0 --timeout 10s
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var ifaces []net.Interface
+		if upnpInterface != "" {
+			iface, err := net.InterfaceByName(upnpInterface)
+			if err != nil {
+				usage.Fatalf(cmd, "failed to find interface %q: %s", upnpInterface, err)
+			}
+			ifaces = append(ifaces, *iface)
+		}
+
+		spinner.Start()
+
+		devices, err := ssdp.Discover(ctx, upnpTimeout, ifaces...)
+		if err != nil {
+			usage.Fatalf(cmd, "failed to discover devices: %s", err)
+		}
+
+		spinner.Stop()
+
+		enc := encoder.New[ssdp.Device](os.Stdout, output)
+		if err := enc.Encode(devices...); err != nil {
+			usage.Fatalf(cmd, "failed to encode devices: %s", err)
+		}
+	},
+}
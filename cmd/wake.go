@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/spf13/cobra"
+
+	"github.com/fuskovic/networker/v3/internal/inventory"
+	"github.com/fuskovic/networker/v3/internal/neighbor"
+	"github.com/fuskovic/networker/v3/internal/resolve"
+	"github.com/fuskovic/networker/v3/internal/usage"
+	"github.com/fuskovic/networker/v3/internal/wol"
+)
+
+var (
+	wakePassword  string
+	wakeInterface string
+	wakePort      int
+)
+
+func init() {
+	wakeCmd.Flags().StringVar(&wakePassword, "password", "", "SecureOn password formatted as a hardware address or an ipv4 address.")
+	wakeCmd.Flags().StringVarP(&wakeInterface, "interface", "i", "", "Interface to broadcast on(defaults to the interface on the same network as the device or every interface if it's unknown).")
+	wakeCmd.Flags().IntVar(&wakePort, "port", wol.DefaultPort, "UDP port to send the magic packet to.")
+	Root.AddCommand(wakeCmd)
+}
+
+var wakeCmd = &cobra.Command{
+	Use:   "wake",
+	Short: "Wake a device by broadcasting a wake-on-lan magic packet.",
+	Example: `
+# Wake a device by its hardware address:
+
+	nw wake 00:11:32:aa:bb:cc
+
+# Wake a device by its label in devices.yaml:
+
+	nw wake nas
+
+# Wake a device by its ip address(resolved from the neighbor table or the inventory):
+
+	nw wake 192.168.1.23
+
+# Wake a device that requires a SecureOn password:
+
+	nw wake 00:11:32:aa:bb:cc --password 11:22:33:44:55:66
+
+# Wake a device by broadcasting on a particular interface:
+
+	nw wake 00:11:32:aa:bb:cc -i eth0
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			password []byte
+			err      error
+		)
+		if wakePassword != "" {
+			password, err = wol.ParsePassword(wakePassword)
+			if err != nil {
+				usage.Fatalf(cmd, "%s", err)
+			}
+		}
+
+		mac, ip := resolveWakeTarget(cmd, args[0])
+
+		packet, err := wol.MagicPacket(mac, password)
+		if err != nil {
+			usage.Fatalf(cmd, "failed to build magic packet: %s", err)
+		}
+
+		targets, err := wol.Targets(ip, wakeInterface)
+		if err != nil {
+			usage.Fatalf(cmd, "failed to find where to broadcast: %s", err)
+		}
+
+		if err := wol.Send(packet, targets, wakePort); err != nil {
+			usage.Fatalf(cmd, "%s", err)
+		}
+
+		for _, t := range targets {
+			fmt.Printf("sent magic packet for %s to %s:%d on %s\n", mac, t.Broadcast, wakePort, t.Interface)
+		}
+	},
+}
+
+// resolveWakeTarget resolves the hardware address of a device identified by its hardware address,
+// label, ip address or hostname. The ip address of the device is returned too if it's known.
+func resolveWakeTarget(cmd *cobra.Command, query string) (net.HardwareAddr, net.IP) {
+	inv, err := inventory.Load()
+	if err != nil {
+		usage.Fatalf(cmd, "failed to load inventory: %s", err)
+	}
+
+	if mac, err := net.ParseMAC(query); err == nil {
+		var ip net.IP
+		if r := inv.Lookup(mac.String()); r != nil {
+			ip = net.ParseIP(r.IP)
+		}
+		return mac, ip
+	}
+
+	if l := loadLabels(cmd).Find(query); l != nil {
+		if mac, err := net.ParseMAC(l.MAC); err == nil {
+			return mac, net.ParseIP(l.IP)
+		}
+		query = l.IP
+	}
+
+	ip := net.ParseIP(query)
+	if ip == nil {
+		if r := inv.Lookup(query); r != nil {
+			ip = net.ParseIP(r.IP)
+		} else if record, err := resolve.AddrByHostName(query); err == nil {
+			ip = record.IP
+		}
+	}
+
+	if ip != nil {
+		// The neighbor table is more up to date than the inventory.
+		if entries, err := neighbor.Table(); err == nil {
+			if entry := neighbor.Lookup(entries, ip); entry != nil {
+				return entry.MAC, ip
+			}
+		}
+		if r := inv.Lookup(ip.String()); r != nil && r.MAC != "" {
+			if mac, err := net.ParseMAC(r.MAC); err == nil {
+				return mac, ip
+			}
+		}
+	}
+
+	if r := inv.Lookup(query); r != nil && r.MAC != "" {
+		if mac, err := net.ParseMAC(r.MAC); err == nil {
+			return mac, net.ParseIP(r.IP)
+		}
+	}
+
+	usage.Fatalf(cmd, "no hardware address found for %q(run nw list to record the devices on the network in the inventory)", query)
+	return nil, nil
+}
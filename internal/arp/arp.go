@@ -0,0 +1,70 @@
+package arp
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+)
+
+const (
+	etherTypeARP  = 0x0806
+	etherTypeIPv4 = 0x0800
+	hwTypeEther   = 1
+
+	opRequest = 1
+	opReply   = 2
+
+	etherHeaderLen = 14
+	arpPacketLen   = 28
+)
+
+var broadcast = net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+// request builds an ethernet frame broadcasting an arp request for target.
+func request(srcMAC net.HardwareAddr, srcIP, target net.IP) []byte {
+	b := make([]byte, etherHeaderLen+arpPacketLen)
+
+	// ethernet header
+	copy(b[0:6], broadcast)
+	copy(b[6:12], srcMAC)
+	binary.BigEndian.PutUint16(b[12:14], etherTypeARP)
+
+	// arp packet
+	p := b[etherHeaderLen:]
+	binary.BigEndian.PutUint16(p[0:2], hwTypeEther)
+	binary.BigEndian.PutUint16(p[2:4], etherTypeIPv4)
+	p[4] = 6
+	p[5] = 4
+	binary.BigEndian.PutUint16(p[6:8], opRequest)
+	copy(p[8:14], srcMAC)
+	copy(p[14:18], srcIP.To4())
+	copy(p[24:28], target.To4())
+	return b
+}
+
+// parseReply parses an ethernet frame containing an arp reply
+// and returns the sender's protocol and hardware address.
+func parseReply(b []byte) (net.IP, net.HardwareAddr, error) {
+	if len(b) < etherHeaderLen+arpPacketLen {
+		return nil, nil, errors.New("frame too short")
+	}
+	if binary.BigEndian.Uint16(b[12:14]) != etherTypeARP {
+		return nil, nil, errors.New("not an arp frame")
+	}
+
+	p := b[etherHeaderLen:]
+	if binary.BigEndian.Uint16(p[0:2]) != hwTypeEther ||
+		binary.BigEndian.Uint16(p[2:4]) != etherTypeIPv4 ||
+		p[4] != 6 || p[5] != 4 {
+		return nil, nil, errors.New("unsupported arp hardware or protocol type")
+	}
+	if binary.BigEndian.Uint16(p[6:8]) != opReply {
+		return nil, nil, errors.New("not an arp reply")
+	}
+
+	mac := make(net.HardwareAddr, 6)
+	copy(mac, p[8:14])
+	ip := make(net.IP, net.IPv4len)
+	copy(ip, p[14:18])
+	return ip, mac, nil
+}
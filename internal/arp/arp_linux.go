@@ -0,0 +1,137 @@
+package arp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/fuskovic/networker/v3/internal/neighbor"
+)
+
+// sendInterval paces the requests so they aren't dropped by the interface or switch.
+const sendInterval = time.Millisecond
+
+// Sweep broadcasts an arp request for each of the targets on iface and returns the targets that replied.
+// Replies are collected until timeout elapses after the last request was sent.
+// It requires the CAP_NET_RAW capability.
+func Sweep(ctx context.Context, iface *net.Interface, src net.IP, targets []net.IP, timeout time.Duration) ([]neighbor.Entry, error) {
+	if len(iface.HardwareAddr) != 6 {
+		return nil, fmt.Errorf("interface %s does not support arp", iface.Name)
+	}
+	if src.To4() == nil {
+		return nil, fmt.Errorf("%s is not an ipv4 address", src)
+	}
+
+	f, err := listen(iface)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		done    = make(chan struct{})
+		replies = make(chan []neighbor.Entry)
+	)
+
+	go func() {
+		replies <- receive(f, iface, targets, done)
+	}()
+
+	for _, target := range targets {
+		if _, err := f.Write(request(iface.HardwareAddr, src, target)); err != nil {
+			close(done)
+			<-replies
+			return nil, fmt.Errorf("failed to send arp request for %s: %w", target, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			close(done)
+			<-replies
+			return nil, ctx.Err()
+		case <-time.After(sendInterval):
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(timeout):
+	}
+	close(done)
+	return <-replies, ctx.Err()
+}
+
+// listen opens a packet socket bound to iface that only receives arp frames.
+func listen(iface *net.Interface) (*os.File, error) {
+	proto := htons(unix.ETH_P_ARP)
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC, int(proto))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open packet socket: %w", err)
+	}
+
+	if err := unix.Bind(fd, &unix.SockaddrLinklayer{Protocol: proto, Ifindex: iface.Index}); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to bind packet socket to %s: %w", iface.Name, err)
+	}
+
+	// non-blocking sockets are registered with the runtime poller which enables read deadlines.
+	if err := unix.SetNonblock(fd, true); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to set packet socket to non-blocking: %w", err)
+	}
+	return os.NewFile(uintptr(fd), "arp:"+iface.Name), nil
+}
+
+// receive collects replies from targets until done is closed.
+func receive(f *os.File, iface *net.Interface, targets []net.IP, done <-chan struct{}) []neighbor.Entry {
+	wanted := make(map[string]bool)
+	for _, target := range targets {
+		wanted[target.To4().String()] = true
+	}
+
+	var (
+		entries []neighbor.Entry
+		buf     = make([]byte, 1500)
+	)
+
+	for {
+		select {
+		case <-done:
+			return entries
+		default:
+		}
+
+		_ = f.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		n, err := f.Read(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				continue
+			}
+			return entries
+		}
+
+		ip, mac, err := parseReply(buf[:n])
+		if err != nil || !wanted[ip.String()] {
+			continue
+		}
+
+		// only record the first reply in case of duplicates
+		delete(wanted, ip.String())
+		entries = append(entries,
+			neighbor.Entry{
+				IP:        ip,
+				MAC:       mac,
+				Interface: iface.Name,
+			},
+		)
+	}
+}
+
+func htons(v uint16) uint16 {
+	return v<<8 | v>>8
+}
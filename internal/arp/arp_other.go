@@ -0,0 +1,18 @@
+//go:build !linux
+
+package arp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"runtime"
+	"time"
+
+	"github.com/fuskovic/networker/v3/internal/neighbor"
+)
+
+// Sweep broadcasts an arp request for each of the targets on iface and returns the targets that replied.
+func Sweep(_ context.Context, _ *net.Interface, _ net.IP, _ []net.IP, _ time.Duration) ([]neighbor.Entry, error) {
+	return nil, errors.New("arp sweeps are not supported on " + runtime.GOOS)
+}
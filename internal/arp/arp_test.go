@@ -0,0 +1,39 @@
+package arp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestARP(t *testing.T) {
+	t.Parallel()
+	srcMAC, _ := net.ParseMAC("02:fc:00:00:00:01")
+	srcIP := net.ParseIP("192.168.1.10")
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("parse reply to request", func(t *testing.T) {
+			// turn the request into the reply the target would send back
+			targetMAC, _ := net.ParseMAC("b8:27:eb:12:34:56")
+			frame := request(targetMAC, net.ParseIP("192.168.1.20"), srcIP)
+			frame[etherHeaderLen+7] = opReply
+
+			ip, mac, err := parseReply(frame)
+			require.NoError(t, err)
+			require.Equal(t, "192.168.1.20", ip.String())
+			require.Equal(t, targetMAC.String(), mac.String())
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("parse request as reply", func(t *testing.T) {
+			ip, mac, err := parseReply(request(srcMAC, srcIP, net.ParseIP("192.168.1.20")))
+			require.Nil(t, ip)
+			require.Nil(t, mac)
+			require.Error(t, err)
+		})
+		t.Run("parse truncated frame", func(t *testing.T) {
+			_, _, err := parseReply(make([]byte, etherHeaderLen))
+			require.Error(t, err)
+		})
+	})
+}
@@ -0,0 +1,162 @@
+package classify
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fuskovic/networker/v3/internal/config"
+)
+
+// FileName is the name of the file in the networker config directory that overrides the embedded rules.
+const FileName = "classify.yaml"
+
+var (
+	//go:embed rules.yaml
+	embedded []byte
+
+	loadOnce sync.Once
+	loaded   Rules
+	loadErr  error
+)
+
+// Device is what is known about a device that can be used to classify it.
+type Device struct {
+	Hostname string
+	Vendor   string
+	// OS and Ports are empty unless the device was fingerprinted.
+	OS       string
+	Ports    []int
+	Services []string
+	UPnPType string
+}
+
+// Rule assigns a kind to the devices that match all of its criteria.
+type Rule struct {
+	Kind      string   `yaml:"kind"`
+	Ports     []int    `yaml:"ports,omitempty"`
+	Services  []string `yaml:"services,omitempty"`
+	UPnP      []string `yaml:"upnp,omitempty"`
+	Vendors   []string `yaml:"vendors,omitempty"`
+	Hostnames []string `yaml:"hostnames,omitempty"`
+	OS        []string `yaml:"os,omitempty"`
+}
+
+// Rules are evaluated in order and the first one a device matches classifies it.
+type Rules []Rule
+
+// Parse parses a yaml rules file.
+func Parse(r io.Reader) (Rules, error) {
+	var file struct {
+		Rules Rules `yaml:"rules"`
+	}
+	// Misspelled criteria would otherwise be ignored and make rules match more devices than intended.
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to decode rules: %w", err)
+	}
+
+	if len(file.Rules) == 0 {
+		return nil, errors.New("no rules found")
+	}
+
+	for i, rule := range file.Rules {
+		if rule.Kind == "" {
+			return nil, fmt.Errorf("rule %d: missing kind", i+1)
+		}
+		if len(rule.Ports)+len(rule.Services)+len(rule.UPnP)+len(rule.Vendors)+len(rule.Hostnames)+len(rule.OS) == 0 {
+			return nil, fmt.Errorf("rule %d: no criteria", i+1)
+		}
+		for _, pattern := range rule.Hostnames {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid hostname pattern %q: %w", i+1, pattern, err)
+			}
+		}
+	}
+	return file.Rules, nil
+}
+
+// Classify returns the kind of the first rule d matches or an empty string if it doesn't match any.
+func (rs Rules) Classify(d Device) string {
+	for _, rule := range rs {
+		if rule.matches(d) {
+			return rule.Kind
+		}
+	}
+	return ""
+}
+
+// Classify classifies d using the rules in the networker config directory, falling back to the embedded rules.
+func Classify(d Device) string {
+	_ = Load()
+	return loaded.Classify(d)
+}
+
+// Load loads the rules in the networker config directory, or the embedded rules if there aren't any.
+// The embedded rules are used if the rules in the config directory can't be read, in which case the error is returned.
+// Classify loads the rules on first use so Load only needs to be called to find out whether they're invalid.
+func Load() error {
+	loadOnce.Do(func() {
+		loaded, loadErr = load()
+	})
+	return loadErr
+}
+
+func load() (Rules, error) {
+	embeddedRules, err := Parse(bytes.NewReader(embedded))
+	if err != nil {
+		panic(fmt.Errorf("failed to parse embedded classification rules: %w", err))
+	}
+
+	path, err := config.Path(FileName)
+	if err != nil {
+		return embeddedRules, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return embeddedRules, nil
+		}
+		return embeddedRules, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	rules, err := Parse(f)
+	if err != nil {
+		return embeddedRules, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+func (r Rule) matches(d Device) bool {
+	hostname := strings.ToLower(strings.TrimSuffix(d.Hostname, "."))
+	return matchAny(r.Ports, func(port int) bool { return slices.Contains(d.Ports, port) }) &&
+		matchAny(r.Services, func(svc string) bool { return slices.Contains(d.Services, svc) }) &&
+		matchAny(r.UPnP, func(s string) bool { return d.UPnPType != "" && containsFold(d.UPnPType, s) }) &&
+		matchAny(r.Vendors, func(s string) bool { return d.Vendor != "" && containsFold(d.Vendor, s) }) &&
+		matchAny(r.OS, func(os string) bool { return strings.EqualFold(d.OS, os) }) &&
+		matchAny(r.Hostnames, func(pattern string) bool {
+			ok, _ := path.Match(strings.ToLower(pattern), hostname)
+			return hostname != "" && ok
+		})
+}
+
+// matchAny reports whether any of criteria match or if there is no criteria to match.
+func matchAny[T any](criteria []T, match func(T) bool) bool {
+	return len(criteria) == 0 || slices.ContainsFunc(criteria, match)
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
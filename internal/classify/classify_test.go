@@ -0,0 +1,122 @@
+package classify
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/fuskovic/networker/v3/internal/test"
+)
+
+func TestClassify(t *testing.T) {
+	t.Run("ShouldPass", func(t *testing.T) {
+		rules, err := Parse(bytes.NewReader(embedded))
+		require.NoError(t, err)
+
+		for _, test := range []struct {
+			name     string
+			device   Device
+			expected string
+		}{
+			{
+				name:     "classify printer using advertised service",
+				device:   Device{Services: []string{"_ipp._tcp"}},
+				expected: "printer",
+			},
+			{
+				name:     "classify camera using rtsp port",
+				device:   Device{Ports: []int{80, 554}},
+				expected: "camera",
+			},
+			{
+				name:     "classify nas using vendor",
+				device:   Device{Vendor: "Synology Incorporated"},
+				expected: "nas",
+			},
+			{
+				name:     "classify tv using upnp device type and vendor",
+				device:   Device{UPnPType: "urn:schemas-upnp-org:device:MediaRenderer:1", Vendor: "Samsung Electronics Co.,Ltd"},
+				expected: "tv",
+			},
+			{
+				name:     "classify phone using hostname",
+				device:   Device{Hostname: "Janes-iPhone.local."},
+				expected: "phone",
+			},
+			{
+				name:     "classify server using os and ports",
+				device:   Device{OS: "linux", Ports: []int{22}},
+				expected: "server",
+			},
+			{
+				name:     "classify workstation using os",
+				device:   Device{OS: "windows", Ports: []int{135, 445}},
+				expected: "workstation",
+			},
+		} {
+			t.Run(test.name, func(t *testing.T) {
+				require.Equal(t, test.expected, rules.Classify(test.device))
+			})
+		}
+		t.Run("load rules from config dir", func(t *testing.T) {
+			path := filepath.Join(test.ConfigDir(t), FileName)
+			require.NoError(t, os.WriteFile(path, []byte("rules:\n  - kind: thermostat\n    hostnames: [\"ecobee*\"]\n"), 0o644))
+
+			rules, err := load()
+			require.NoError(t, err)
+			require.Equal(t, "thermostat", rules.Classify(Device{Hostname: "ecobee-hallway"}))
+		})
+		t.Run("load embedded rules without a config file", func(t *testing.T) {
+			test.ConfigDir(t)
+
+			rules, err := load()
+			require.NoError(t, err)
+			require.Equal(t, "printer", rules.Classify(Device{Ports: []int{9100}}))
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("load invalid rules from config dir", func(t *testing.T) {
+			path := filepath.Join(test.ConfigDir(t), FileName)
+			require.NoError(t, os.WriteFile(path, []byte("rules:\n  - kind: thermostat\n    hostname: [\"ecobee*\"]\n"), 0o644))
+
+			// The embedded rules are still used to classify devices.
+			rules, err := load()
+			require.ErrorContains(t, err, path)
+			require.Equal(t, "printer", rules.Classify(Device{Ports: []int{9100}}))
+		})
+		t.Run("classify device that doesn't match any rule", func(t *testing.T) {
+			rules, err := Parse(bytes.NewReader(embedded))
+			require.NoError(t, err)
+			require.Empty(t, rules.Classify(Device{Hostname: "unknown", Ports: []int{8080}}))
+		})
+		t.Run("classify tv using upnp device type without tv vendor", func(t *testing.T) {
+			rules, err := Parse(bytes.NewReader(embedded))
+			require.NoError(t, err)
+			require.Empty(t, rules.Classify(Device{UPnPType: "urn:schemas-upnp-org:device:MediaRenderer:1"}))
+		})
+		t.Run("parse rule without criteria", func(t *testing.T) {
+			rules, err := Parse(strings.NewReader("rules:\n  - kind: printer\n"))
+			require.Nil(t, rules)
+			require.Error(t, err)
+		})
+		t.Run("parse rule with misspelled criteria", func(t *testing.T) {
+			rules, err := Parse(strings.NewReader("rules:\n  - kind: printer\n    ports: [9100]\n    vendor: [brother]\n"))
+			require.Nil(t, rules)
+			require.Error(t, err)
+		})
+		t.Run("parse rule without kind", func(t *testing.T) {
+			rules, err := Parse(strings.NewReader("rules:\n  - ports: [9100]\n"))
+			require.Nil(t, rules)
+			require.Error(t, err)
+		})
+		t.Run("parse rule with invalid hostname pattern", func(t *testing.T) {
+			rules, err := Parse(strings.NewReader("rules:\n  - kind: printer\n    hostnames: [\"[\"]\n"))
+			require.Nil(t, rules)
+			require.Error(t, err)
+		})
+	})
+}
@@ -0,0 +1,63 @@
+package iface
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const procNetDev = "/proc/net/dev"
+
+// counters returns the received and transmitted counters of every interface by name.
+func counters() (map[string]Counters, map[string]Counters, error) {
+	f, err := os.Open(procNetDev)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %w", procNetDev, err)
+	}
+	defer f.Close()
+
+	rx, tx, err := parseDev(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", procNetDev, err)
+	}
+	return rx, tx, nil
+}
+
+// parseDev parses the contents of /proc/net/dev.
+func parseDev(r io.Reader) (map[string]Counters, map[string]Counters, error) {
+	var (
+		rx      = make(map[string]Counters)
+		tx      = make(map[string]Counters)
+		scanner = bufio.NewScanner(r)
+	)
+	for scanner.Scan() {
+		// The column headers don't have a colon separating the interface name from its counters.
+		name, values, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Fields(values)
+		if len(fields) < 16 {
+			continue
+		}
+
+		var n [16]uint64
+		for i := range n {
+			v, err := strconv.ParseUint(fields[i], 10, 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid counter %q: %w", fields[i], err)
+			}
+			n[i] = v
+		}
+
+		// The first 8 columns are receive counters and the last 8 are transmit counters.
+		name = strings.TrimSpace(name)
+		rx[name] = Counters{Bytes: n[0], Packets: n[1], Errors: n[2], Drops: n[3]}
+		tx[name] = Counters{Bytes: n[8], Packets: n[9], Errors: n[10], Drops: n[11]}
+	}
+	return rx, tx, scanner.Err()
+}
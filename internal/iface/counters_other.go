@@ -0,0 +1,13 @@
+//go:build !linux
+
+package iface
+
+import (
+	"errors"
+	"runtime"
+)
+
+// counters returns the received and transmitted counters of every interface by name.
+func counters() (map[string]Counters, map[string]Counters, error) {
+	return nil, nil, errors.New("reading interface counters is not supported on " + runtime.GOOS)
+}
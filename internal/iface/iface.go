@@ -0,0 +1,111 @@
+package iface
+
+import (
+	"fmt"
+	"net"
+)
+
+// Interface is a network interface and the addresses assigned to it.
+type Interface struct {
+	Index int    `json:"index" yaml:"index" table:"INDEX"`
+	Name  string `json:"name" yaml:"name" table:"NAME"`
+	Flags string `json:"flags" yaml:"flags" table:"FLAGS"`
+	MTU   int    `json:"mtu" yaml:"mtu" table:"MTU"`
+	MAC   string `json:"mac,omitempty" yaml:"mac,omitempty" table:"MAC"`
+	// IPv4 and IPv6 are the addresses of the interface in cidr notation.
+	IPv4 []string `json:"ipv4,omitempty" yaml:"ipv4,omitempty" table:"IPV4"`
+	IPv6 []string `json:"ipv6,omitempty" yaml:"ipv6,omitempty" table:"IPV6"`
+	// RX and TX are nil if the counters of the interface can't be read.
+	RX *Counters `json:"rx,omitempty" yaml:"rx,omitempty" table:"RX"`
+	TX *Counters `json:"tx,omitempty" yaml:"tx,omitempty" table:"TX"`
+}
+
+// Counters are the traffic an interface has received or transmitted since it was brought up.
+type Counters struct {
+	Bytes   uint64 `json:"bytes" yaml:"bytes"`
+	Packets uint64 `json:"packets" yaml:"packets"`
+	Errors  uint64 `json:"errors" yaml:"errors"`
+	Drops   uint64 `json:"drops" yaml:"drops"`
+}
+
+func (c *Counters) String() string {
+	if c == nil {
+		return "N/A"
+	}
+	s := fmt.Sprintf("%s %d pkts", bytes(c.Bytes), c.Packets)
+	// Errors and drops are only shown when there are any since they're usually zero.
+	if c.Errors > 0 || c.Drops > 0 {
+		s += fmt.Sprintf(" %d errs %d drops", c.Errors, c.Drops)
+	}
+	return s
+}
+
+// List returns every network interface, or only the one with name if it isn't empty.
+func List(name string) ([]Interface, error) {
+	var ifaces []net.Interface
+	if name != "" {
+		i, err := net.InterfaceByName(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find interface %q: %w", name, err)
+		}
+		ifaces = []net.Interface{*i}
+	} else {
+		all, err := net.Interfaces()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list interfaces: %w", err)
+		}
+		ifaces = all
+	}
+
+	// Counters aren't available on every platform so they're left out if they can't be read.
+	rx, tx, _ := counters()
+
+	list := make([]Interface, len(ifaces))
+	for i, ifc := range ifaces {
+		list[i] = Interface{
+			Index: ifc.Index,
+			Name:  ifc.Name,
+			Flags: ifc.Flags.String(),
+			MTU:   ifc.MTU,
+			MAC:   ifc.HardwareAddr.String(),
+		}
+
+		addrs, err := ifc.Addrs()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list addresses of %s: %w", ifc.Name, err)
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			if ipNet.IP.To4() != nil {
+				list[i].IPv4 = append(list[i].IPv4, ipNet.String())
+			} else {
+				list[i].IPv6 = append(list[i].IPv6, ipNet.String())
+			}
+		}
+
+		if c, ok := rx[ifc.Name]; ok {
+			list[i].RX = &c
+		}
+		if c, ok := tx[ifc.Name]; ok {
+			list[i].TX = &c
+		}
+	}
+	return list, nil
+}
+
+// bytes formats n with the largest binary unit it's at least one of.
+func bytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
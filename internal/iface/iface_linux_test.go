@@ -0,0 +1,53 @@
+package iface
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIface(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("list loopback interface", func(t *testing.T) {
+			ifaces, err := List("lo")
+			require.NoError(t, err)
+			require.Len(t, ifaces, 1)
+			require.Equal(t, "lo", ifaces[0].Name)
+			require.Contains(t, ifaces[0].Flags, "loopback")
+			require.Contains(t, ifaces[0].IPv4, "127.0.0.1/8")
+			require.NotNil(t, ifaces[0].RX)
+			require.NotNil(t, ifaces[0].TX)
+		})
+		t.Run("parse counters from /proc/net/dev", func(t *testing.T) {
+			dev := strings.Join([]string{
+				"Inter-|   Receive                                                |  Transmit",
+				" face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed",
+				"    lo:    8120      98    0    0    0     0          0         0     8120      98    0    0    0     0       0          0",
+				"  eth0: 2097152    1500    3    7    0     0          0        12   524288     900    1    2    0     0       0          0",
+			}, "\n")
+			rx, tx, err := parseDev(strings.NewReader(dev))
+			require.NoError(t, err)
+			require.Len(t, rx, 2)
+			require.Equal(t, Counters{Bytes: 2097152, Packets: 1500, Errors: 3, Drops: 7}, rx["eth0"])
+			require.Equal(t, Counters{Bytes: 524288, Packets: 900, Errors: 1, Drops: 2}, tx["eth0"])
+			require.Equal(t, uint64(98), tx["lo"].Packets)
+		})
+		t.Run("format counters", func(t *testing.T) {
+			require.Equal(t, "2.0MiB 1500 pkts 3 errs 7 drops", (&Counters{Bytes: 2097152, Packets: 1500, Errors: 3, Drops: 7}).String())
+			require.Equal(t, "512B 4 pkts", (&Counters{Bytes: 512, Packets: 4}).String())
+			require.Equal(t, "N/A", (*Counters)(nil).String())
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("list interface that doesn't exist", func(t *testing.T) {
+			_, err := List("doesnotexist0")
+			require.Error(t, err)
+		})
+		t.Run("parse invalid counters", func(t *testing.T) {
+			_, _, err := parseDev(strings.NewReader("eth0: 1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 x"))
+			require.Error(t, err)
+		})
+	})
+}
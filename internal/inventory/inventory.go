@@ -0,0 +1,270 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fuskovic/networker/v3/internal/config"
+)
+
+// FileName is the name of the inventory file in the networker config directory.
+const FileName = "inventory.json"
+
+// Inventory is every device that has been seen on the network.
+type Inventory struct {
+	// Devices are keyed by hardware address or by ip address if the hardware address isn't known.
+	Devices map[string]*Record `json:"devices"`
+	path    string
+}
+
+// Record is the history of a device.
+type Record struct {
+	ID        string        `json:"id" yaml:"id" table:"-"`
+	MAC       string        `json:"mac,omitempty" yaml:"mac,omitempty" table:"MAC"`
+	Vendor    string        `json:"vendor,omitempty" yaml:"vendor,omitempty" table:"VENDOR"`
+	Kind      string        `json:"kind" yaml:"kind" table:"KIND"`
+	Label     string        `json:"label,omitempty" yaml:"label,omitempty" table:"LABEL"`
+	Owner     string        `json:"owner,omitempty" yaml:"owner,omitempty" table:"-"`
+	Tags      []string      `json:"tags,omitempty" yaml:"tags,omitempty" table:"TAGS"`
+	Hostname  string        `json:"hostname" yaml:"hostname" table:"HOSTNAME"`
+	IP        string        `json:"ip" yaml:"ip" table:"IP"`
+	FirstSeen time.Time     `json:"first_seen" yaml:"first_seen" table:"FIRST_SEEN"`
+	LastSeen  time.Time     `json:"last_seen" yaml:"last_seen" table:"LAST_SEEN"`
+	IPs       []Observation `json:"ips" yaml:"ips" table:"-"`
+	Hostnames []Observation `json:"hostnames" yaml:"hostnames" table:"-"`
+}
+
+// Observation is a value a device has had and when it was seen with it.
+type Observation struct {
+	Value     string    `json:"value" yaml:"value" table:"VALUE"`
+	FirstSeen time.Time `json:"first_seen" yaml:"first_seen" table:"FIRST_SEEN"`
+	LastSeen  time.Time `json:"last_seen" yaml:"last_seen" table:"LAST_SEEN"`
+}
+
+// Sighting is a device seen on the network.
+type Sighting struct {
+	MAC      string
+	IP       string
+	Hostname string
+	Vendor   string
+	Kind     string
+}
+
+// Load reads the inventory from the networker config directory.
+// An empty inventory is returned if no devices have been recorded yet.
+func Load() (*Inventory, error) {
+	path, err := config.Path(FileName)
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &Inventory{
+		Devices: make(map[string]*Record),
+		path:    path,
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return inv, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(b, inv); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	if inv.Devices == nil {
+		inv.Devices = make(map[string]*Record)
+	}
+	return inv, nil
+}
+
+// Save writes the inventory to the networker config directory.
+// The file is replaced atomically so an interrupted write can't corrupt it.
+func (inv *Inventory) Save() error {
+	if err := os.MkdirAll(filepath.Dir(inv.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(inv.path), err)
+	}
+
+	b, err := json.MarshalIndent(inv, "", "\t")
+	if err != nil {
+		return fmt.Errorf("failed to encode inventory: %w", err)
+	}
+
+	tmp := inv.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+
+	if err := os.Rename(tmp, inv.path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", inv.path, err)
+	}
+	return nil
+}
+
+// Observe records that s was seen at t.
+func (inv *Inventory) Observe(s Sighting, t time.Time) {
+	t = t.Truncate(time.Second)
+	id := s.MAC
+	if id == "" {
+		id = s.IP
+	}
+	if id == "" {
+		return
+	}
+
+	r, ok := inv.Devices[id]
+	if !ok {
+		r = &Record{ID: id, FirstSeen: t}
+		inv.Devices[id] = r
+	}
+
+	// Devices recorded before their hardware address was known are merged into their new record.
+	if s.MAC != "" && s.IP != "" {
+		if previous, ok := inv.Devices[s.IP]; ok && previous.MAC == "" {
+			r.merge(previous)
+			delete(inv.Devices, s.IP)
+		}
+	}
+
+	r.MAC = s.MAC
+	r.IP = s.IP
+	r.LastSeen = t
+	if s.Vendor != "" {
+		r.Vendor = s.Vendor
+	}
+	if s.Kind != "" {
+		r.Kind = s.Kind
+	}
+	if s.Hostname != "" {
+		r.Hostname = s.Hostname
+		r.Hostnames = observe(r.Hostnames, s.Hostname, t)
+	}
+	if s.IP != "" {
+		r.IPs = observe(r.IPs, s.IP, t)
+	}
+}
+
+// Lookup returns the record of the device identified by query which is either a hardware address,
+// an ip address or a hostname the device currently has. The record of a device that used to have
+// the ip address or hostname is returned if no device currently has it.
+func (inv *Inventory) Lookup(query string) *Record {
+	if query == "" {
+		return nil
+	}
+
+	if mac, err := net.ParseMAC(query); err == nil {
+		query = mac.String()
+	}
+
+	if r, ok := inv.Devices[query]; ok {
+		return r
+	}
+
+	records := inv.Records()
+	for i := range records {
+		if records[i].IP == query || strings.EqualFold(records[i].Hostname, query) {
+			return inv.Devices[records[i].ID]
+		}
+	}
+
+	for i := range records {
+		for _, observations := range [][]Observation{records[i].IPs, records[i].Hostnames} {
+			for _, o := range observations {
+				if strings.EqualFold(o.Value, query) {
+					return inv.Devices[records[i].ID]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Remove forgets the device identified by query. It returns false if no device was found.
+func (inv *Inventory) Remove(query string) bool {
+	r := inv.Lookup(query)
+	if r == nil {
+		return false
+	}
+	delete(inv.Devices, r.ID)
+	return true
+}
+
+// Records returns every device in the inventory starting with the most recently seen.
+func (inv *Inventory) Records() []Record {
+	var records []Record
+	for _, r := range inv.Devices {
+		records = append(records, *r)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].LastSeen.Equal(records[j].LastSeen) {
+			return records[i].LastSeen.After(records[j].LastSeen)
+		}
+		return records[i].ID < records[j].ID
+	})
+	return records
+}
+
+// merge adds the history of other to r.
+func (r *Record) merge(other *Record) {
+	if other.FirstSeen.Before(r.FirstSeen) {
+		r.FirstSeen = other.FirstSeen
+	}
+	for _, o := range other.IPs {
+		r.IPs = observe(r.IPs, o.Value, o.FirstSeen)
+		r.IPs = observe(r.IPs, o.Value, o.LastSeen)
+	}
+	for _, o := range other.Hostnames {
+		r.Hostnames = observe(r.Hostnames, o.Value, o.FirstSeen)
+		r.Hostnames = observe(r.Hostnames, o.Value, o.LastSeen)
+	}
+}
+
+// observe records that value was seen at t.
+func observe(observations []Observation, value string, t time.Time) []Observation {
+	for i := range observations {
+		if observations[i].Value != value {
+			continue
+		}
+		if t.Before(observations[i].FirstSeen) {
+			observations[i].FirstSeen = t
+		}
+		if t.After(observations[i].LastSeen) {
+			observations[i].LastSeen = t
+		}
+		return observations
+	}
+	return append(observations, Observation{value, t, t})
+}
+
+// Change is an ip address or hostname a device has had.
+type Change struct {
+	Field       string `json:"field" yaml:"field" table:"FIELD"`
+	Observation `yaml:",inline" table:"_"`
+}
+
+// History returns the ip addresses and hostnames of r in the order they were first seen.
+func (r Record) History() []Change {
+	var changes []Change
+	for _, o := range r.IPs {
+		changes = append(changes, Change{"ip", o})
+	}
+	for _, o := range r.Hostnames {
+		changes = append(changes, Change{"hostname", o})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].FirstSeen.Before(changes[j].FirstSeen)
+	})
+	return changes
+}
@@ -0,0 +1,83 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/fuskovic/networker/v3/internal/test"
+)
+
+func TestInventory(t *testing.T) {
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("track first seen, last seen and ip history across runs", func(t *testing.T) {
+			test.ConfigDir(t)
+
+			first := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			second := first.Add(24 * time.Hour)
+
+			inv, err := Load()
+			require.NoError(t, err)
+			inv.Observe(Sighting{MAC: "00:11:32:aa:bb:cc", IP: "192.168.1.23", Hostname: "nas.lan."}, first)
+			require.NoError(t, inv.Save())
+
+			inv, err = Load()
+			require.NoError(t, err)
+			inv.Observe(Sighting{MAC: "00:11:32:aa:bb:cc", IP: "192.168.1.42", Hostname: "nas.lan."}, second)
+			require.NoError(t, inv.Save())
+
+			inv, err = Load()
+			require.NoError(t, err)
+			r := inv.Lookup("00:11:32:AA:BB:CC")
+			require.NotNil(t, r)
+			require.Equal(t, first, r.FirstSeen.UTC())
+			require.Equal(t, second, r.LastSeen.UTC())
+			require.Equal(t, "192.168.1.42", r.IP)
+			require.Len(t, r.IPs, 2)
+			require.Len(t, r.Hostnames, 1)
+			require.Len(t, r.History(), 3)
+
+			// previous ip addresses still identify the device
+			require.Equal(t, r, inv.Lookup("192.168.1.23"))
+		})
+		t.Run("merge device recorded by ip once its hardware address is known", func(t *testing.T) {
+			test.ConfigDir(t)
+
+			first := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			inv, err := Load()
+			require.NoError(t, err)
+			inv.Observe(Sighting{IP: "192.168.1.50"}, first)
+			inv.Observe(Sighting{MAC: "b8:27:eb:12:34:56", IP: "192.168.1.50"}, first.Add(time.Hour))
+
+			require.Len(t, inv.Records(), 1)
+			r := inv.Lookup("192.168.1.50")
+			require.NotNil(t, r)
+			require.Equal(t, "b8:27:eb:12:34:56", r.ID)
+			require.Equal(t, first, r.FirstSeen)
+		})
+		t.Run("remove device", func(t *testing.T) {
+			inv := &Inventory{Devices: make(map[string]*Record)}
+			inv.Observe(Sighting{MAC: "00:11:32:aa:bb:cc", IP: "192.168.1.23"}, time.Now())
+			require.True(t, inv.Remove("192.168.1.23"))
+			require.Empty(t, inv.Records())
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("lookup unknown device", func(t *testing.T) {
+			inv := &Inventory{Devices: make(map[string]*Record)}
+			require.Nil(t, inv.Lookup("192.168.1.23"))
+			require.False(t, inv.Remove("192.168.1.23"))
+		})
+		t.Run("load corrupt inventory", func(t *testing.T) {
+			path := filepath.Join(test.ConfigDir(t), FileName)
+			require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+
+			inv, err := Load()
+			require.Nil(t, inv)
+			require.Error(t, err)
+		})
+	})
+}
@@ -0,0 +1,126 @@
+package labels
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/fuskovic/networker/v3/internal/config"
+)
+
+// FileName is the name of the file in the networker config directory that labels devices.
+const FileName = "devices.yaml"
+
+// Label is what we call a device identified by its hardware address or ip address.
+type Label struct {
+	MAC   string   `yaml:"mac,omitempty"`
+	IP    string   `yaml:"ip,omitempty"`
+	Name  string   `yaml:"label"`
+	Owner string   `yaml:"owner,omitempty"`
+	Tags  []string `yaml:"tags,omitempty"`
+}
+
+// Labels are the labeled devices.
+type Labels []Label
+
+// Load reads the labels from the networker config directory.
+// No labels are returned if the file doesn't exist.
+func Load() (Labels, error) {
+	path, err := config.Path(FileName)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	ls, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return ls, nil
+}
+
+// Parse parses a yaml labels file.
+func Parse(r io.Reader) (Labels, error) {
+	var file struct {
+		Devices Labels `yaml:"devices"`
+	}
+	if err := yaml.NewDecoder(r).Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode labels: %w", err)
+	}
+
+	for i, l := range file.Devices {
+		if l.MAC == "" && l.IP == "" {
+			return nil, fmt.Errorf("device %d: missing mac or ip", i+1)
+		}
+
+		if l.MAC != "" {
+			mac, err := net.ParseMAC(l.MAC)
+			if err != nil {
+				return nil, fmt.Errorf("device %d: invalid mac %q", i+1, l.MAC)
+			}
+			file.Devices[i].MAC = mac.String()
+		}
+
+		if l.IP != "" {
+			ip := net.ParseIP(l.IP)
+			if ip == nil {
+				return nil, fmt.Errorf("device %d: invalid ip %q", i+1, l.IP)
+			}
+			file.Devices[i].IP = ip.String()
+		}
+	}
+	return file.Devices, nil
+}
+
+// Lookup returns the label of the device with mac or ip. Labels of hardware addresses take
+// precedence over labels of ip addresses since ip addresses are often reassigned.
+func (ls Labels) Lookup(mac, ip string) *Label {
+	if mac != "" {
+		for i := range ls {
+			if ls[i].MAC == mac {
+				return &ls[i]
+			}
+		}
+	}
+
+	if ip != "" {
+		for i := range ls {
+			if ls[i].IP == ip && (ls[i].MAC == "" || mac == "") {
+				return &ls[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Find returns the label named name.
+func (ls Labels) Find(name string) *Label {
+	for i := range ls {
+		if strings.EqualFold(ls[i].Name, name) {
+			return &ls[i]
+		}
+	}
+	return nil
+}
+
+// HasAnyTag reports whether tags contains any of want.
+func HasAnyTag(tags, want []string) bool {
+	return slices.ContainsFunc(want, func(tag string) bool {
+		return slices.ContainsFunc(tags, func(t string) bool { return strings.EqualFold(t, tag) })
+	})
+}
@@ -0,0 +1,88 @@
+package labels
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/fuskovic/networker/v3/internal/test"
+)
+
+const devicesYAML = `
+devices:
+  - mac: 00:11:32:AA:BB:CC
+    ip: 192.168.1.23
+    label: nas
+    owner: it
+    tags: [storage, critical]
+  - ip: 192.168.1.50
+    label: front-door-camera
+    tags: [camera]
+`
+
+func TestLabels(t *testing.T) {
+	t.Run("ShouldPass", func(t *testing.T) {
+		ls, err := Parse(strings.NewReader(devicesYAML))
+		require.NoError(t, err)
+
+		t.Run("lookup label by hardware address", func(t *testing.T) {
+			l := ls.Lookup("00:11:32:aa:bb:cc", "192.168.1.99")
+			require.NotNil(t, l)
+			require.Equal(t, "nas", l.Name)
+			require.Equal(t, "it", l.Owner)
+		})
+		t.Run("lookup label by ip address of device with unknown hardware address", func(t *testing.T) {
+			l := ls.Lookup("", "192.168.1.23")
+			require.NotNil(t, l)
+			require.Equal(t, "nas", l.Name)
+
+			l = ls.Lookup("02:00:00:00:00:01", "192.168.1.50")
+			require.NotNil(t, l)
+			require.Equal(t, "front-door-camera", l.Name)
+		})
+		t.Run("find label by name", func(t *testing.T) {
+			l := ls.Find("NAS")
+			require.NotNil(t, l)
+			require.True(t, HasAnyTag(l.Tags, []string{"Critical", "printer"}))
+		})
+		t.Run("load labels from config dir", func(t *testing.T) {
+			dir := test.ConfigDir(t)
+
+			ls, err := Load()
+			require.NoError(t, err)
+			require.Empty(t, ls)
+
+			path := filepath.Join(dir, FileName)
+			require.NoError(t, os.WriteFile(path, []byte(devicesYAML), 0o644))
+
+			ls, err = Load()
+			require.NoError(t, err)
+			require.Len(t, ls, 2)
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		ls, err := Parse(strings.NewReader(devicesYAML))
+		require.NoError(t, err)
+
+		t.Run("lookup ip address labeled for a different hardware address", func(t *testing.T) {
+			require.Nil(t, ls.Lookup("02:00:00:00:00:01", "192.168.1.23"))
+		})
+		t.Run("match tags", func(t *testing.T) {
+			require.False(t, HasAnyTag(ls.Find("nas").Tags, []string{"camera"}))
+			require.False(t, HasAnyTag(nil, []string{"camera"}))
+		})
+		t.Run("parse device without mac or ip", func(t *testing.T) {
+			ls, err := Parse(strings.NewReader("devices:\n  - label: nas\n"))
+			require.Nil(t, ls)
+			require.Error(t, err)
+		})
+		t.Run("parse device with invalid mac", func(t *testing.T) {
+			ls, err := Parse(strings.NewReader("devices:\n  - mac: nope\n    label: nas\n"))
+			require.Nil(t, ls)
+			require.Error(t, err)
+		})
+	})
+}
@@ -0,0 +1,35 @@
+package list
+
+import (
+	"github.com/fuskovic/networker/v3/internal/classify"
+)
+
+// classifyDevices replaces the kind of each peer with a more specific one using the classification rules.
+// Peers that don't match any rule keep their kind.
+func classifyDevices(devices []Device) {
+	for i := range devices {
+		d := &devices[i]
+		if d.Kind != DeviceKindPeer {
+			continue
+		}
+
+		device := classify.Device{
+			Vendor:   d.Vendor,
+			Ports:    d.OpenPorts,
+			Services: d.Services,
+		}
+		if d.Hostname != notAvailable {
+			device.Hostname = d.Hostname
+		}
+		if d.OS != nil {
+			device.OS = string(d.OS.OS)
+		}
+		if d.UPnP != nil {
+			device.UPnPType = d.UPnP.DeviceType
+		}
+
+		if kind := classify.Classify(device); kind != "" {
+			d.Kind = Kind(kind)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package list
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fuskovic/networker/v3/internal/llmnr"
+	"github.com/fuskovic/networker/v3/internal/mdns"
+	"github.com/fuskovic/networker/v3/internal/netbios"
+	"github.com/fuskovic/networker/v3/internal/oui"
+	"github.com/fuskovic/networker/v3/internal/ssdp"
+)
+
+const (
+	// discoveryTimeout is how long to wait for responses to service discovery and name resolution queries.
+	discoveryTimeout = 2 * time.Second
+)
+
+const (
+	NameSourceDNS     NameSource = "dns"
+	NameSourceMDNS    NameSource = "mdns"
+	NameSourceNetBIOS NameSource = "netbios"
+	NameSourceLLMNR   NameSource = "llmnr"
+)
+
+// NameSource is the protocol a hostname was resolved with.
+type NameSource string
+
+// discovery is what was learned about devices from service discovery and name resolution protocols.
+type discovery struct {
+	services        []mdns.Service
+	mdnsHostnames   map[string]string
+	upnpDevices     []ssdp.Device
+	netbiosStatuses map[string]netbios.Status
+	llmnrHostnames  map[string]string
+}
+
+// discover concurrently queries devices using each of the protocols enabled in opts.
+// Every protocol is best-effort since most devices only respond to some of them.
+func discover(ctx context.Context, networks []network, devices []Device, opts Options) discovery {
+	var (
+		d       discovery
+		ifaces  = interfaces(networks)
+		ips     []net.IP
+		unnamed []net.IP
+		wg      sync.WaitGroup
+	)
+
+	// Only devices that were found are queried so absent hosts of the sweep aren't flooded with requests.
+	for _, device := range devices {
+		if !device.Present() {
+			continue
+		}
+		ips = append(ips, device.LocalIP)
+		if device.Hostname == notAvailable {
+			unnamed = append(unnamed, device.LocalIP)
+		}
+	}
+
+	run := func(enabled bool, fn func()) {
+		if !enabled {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+	}
+
+	run(opts.MDNS, func() {
+		d.services, _ = mdns.Browse(ctx, discoveryTimeout, ifaces...)
+	})
+	run(opts.MDNS && len(unnamed) > 0, func() {
+		d.mdnsHostnames, _ = mdns.LookupAddr(ctx, discoveryTimeout, unnamed, ifaces...)
+	})
+	run(opts.SSDP, func() {
+		d.upnpDevices, _ = ssdp.Discover(ctx, discoveryTimeout, ifaces...)
+	})
+	// Named devices are queried too since netbios is the only source of workgroups.
+	run(opts.NetBIOS && len(ips) > 0, func() {
+		d.netbiosStatuses, _ = netbios.NodeStatus(ctx, discoveryTimeout, ips)
+	})
+	run(opts.LLMNR && len(unnamed) > 0, func() {
+		d.llmnrHostnames, _ = llmnr.LookupAddr(ctx, discoveryTimeout, unnamed)
+	})
+	wg.Wait()
+	return d
+}
+
+// apply fills in the fields of devices with what was discovered.
+// Hostnames that couldn't be resolved with reverse dns fall back to mdns, netbios and then llmnr.
+func (d discovery) apply(devices []Device) {
+	for i := range devices {
+		device := &devices[i]
+		ip := device.LocalIP.String()
+
+		if device.Hostname != notAvailable && device.HostnameSource == "" {
+			device.HostnameSource = NameSourceDNS
+		}
+
+		if hostname, ok := d.mdnsHostnames[ip]; ok {
+			device.setHostname(hostname, NameSourceMDNS)
+		}
+
+		for _, svc := range d.services {
+			if !slices.ContainsFunc(svc.IPs, device.LocalIP.Equal) {
+				continue
+			}
+			device.setHostname(svc.Host, NameSourceMDNS)
+			if !slices.Contains(device.Services, svc.Type) {
+				device.Services = append(device.Services, svc.Type)
+			}
+		}
+
+		// A device may expose several root devices in which case the first one with a friendly name is used.
+		for _, upnp := range d.upnpDevices {
+			if !upnp.IP.Equal(device.LocalIP) {
+				continue
+			}
+			if device.UPnP == nil || device.UPnP.FriendlyName == "" {
+				upnp := upnp
+				device.UPnP = &upnp
+			}
+		}
+
+		if status, ok := d.netbiosStatuses[ip]; ok {
+			device.setHostname(status.Name, NameSourceNetBIOS)
+			device.Workgroup = status.Workgroup
+			if device.MAC == "" && len(status.MAC) > 0 {
+				device.MAC = status.MAC.String()
+				device.Vendor = oui.Vendor(status.MAC)
+			}
+		}
+
+		if hostname, ok := d.llmnrHostnames[ip]; ok {
+			device.setHostname(hostname, NameSourceLLMNR)
+		}
+	}
+}
+
+// setHostname sets the hostname of d if it hasn't been resolved yet.
+func (d *Device) setHostname(hostname string, source NameSource) {
+	if d.Hostname != notAvailable || hostname == "" {
+		return
+	}
+	d.Hostname = hostname
+	d.HostnameSource = source
+}
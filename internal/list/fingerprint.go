@@ -0,0 +1,107 @@
+package list
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fuskovic/networker/v3/internal/osfp"
+)
+
+const (
+	// fingerprintTimeout is how long to wait for a port to accept a connection.
+	fingerprintTimeout = time.Second
+)
+
+// fingerprintPorts are the ports probed on each device since they're indicative of its operating system or kind.
+var fingerprintPorts = []int{22, 80, 135, 139, 443, 445, 515, 548, 554, 631, 1883, 3389, 8883, 9100, 62078}
+
+// fingerprint guesses the operating system of each device that is present on the network.
+// The ttl is taken from the echo replies of the sweep so the only active probes are the
+// connections to fingerprintPorts. Devices that don't respond to pings may still accept
+// connections(e.g. windows hosts block pings by default) so every device with a known
+// hardware address is probed too.
+func fingerprint(ctx context.Context, devices []Device) {
+	// Handshake responses can only be captured by privileged users so they're best-effort.
+	capture, _ := osfp.Listen()
+	defer capture.Close()
+
+	signals := make([]osfp.Signals, len(devices))
+	for i, d := range devices {
+		if d.Ping != nil {
+			signals[i].TTL = d.Ping.TTL
+		}
+	}
+	for i, ports := range openPorts(ctx, devices, fingerprintPorts) {
+		signals[i].OpenPorts = ports
+	}
+
+	for i := range devices {
+		guess := osfp.Local()
+		if devices[i].Kind != DeviceKindCurrent {
+			signals[i].SYNACK = capture.SYNACK(devices[i].LocalIP, signals[i].OpenPorts...)
+			guess = osfp.Match(signals[i])
+		}
+		devices[i].OS = &guess
+		devices[i].OpenPorts = signals[i].OpenPorts
+		devices[i].Up = devices[i].Up || len(signals[i].OpenPorts) > 0
+	}
+}
+
+// openPorts returns which of ports accept tcp connections on each device that is present on the network by its index.
+// No more than sweepWorkers connections are attempted at once.
+func openPorts(ctx context.Context, devices []Device, ports []int) map[int][]int {
+	type probe struct {
+		device int
+		port   int
+	}
+
+	var probes []probe
+	for i, d := range devices {
+		if d.Kind == DeviceKindCurrent || !d.Present() {
+			continue
+		}
+		for _, port := range ports {
+			probes = append(probes, probe{device: i, port: port})
+		}
+	}
+
+	var (
+		open   = make(map[int][]int)
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		queue  = make(chan probe)
+		dialer = net.Dialer{Timeout: fingerprintTimeout}
+	)
+	for i := 0; i < min(sweepWorkers, len(probes)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range queue {
+				addr := net.JoinHostPort(devices[p.device].LocalIP.String(), strconv.Itoa(p.port))
+				conn, err := dialer.DialContext(ctx, "tcp", addr)
+				if err != nil {
+					continue
+				}
+				conn.Close()
+
+				mu.Lock()
+				open[p.device] = append(open[p.device], p.port)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, p := range probes {
+		queue <- p
+	}
+	close(queue)
+	wg.Wait()
+
+	for _, ports := range open {
+		slices.Sort(ports)
+	}
+	return open
+}
@@ -0,0 +1,103 @@
+package list
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/fuskovic/networker/v3/internal/neighbor"
+	"github.com/fuskovic/networker/v3/internal/oui"
+	"github.com/fuskovic/networker/v3/internal/ping"
+	"github.com/fuskovic/networker/v3/internal/resolve"
+)
+
+const (
+	// ipv6Timeout is how long to wait for replies to the all-nodes echo request.
+	ipv6Timeout = 2 * time.Second
+)
+
+// ula is the unique local address range(RFC 4193).
+var ula = &net.IPNet{IP: net.ParseIP("fc00::"), Mask: net.CIDRMask(7, 128)}
+
+// discoverIPv6 returns the ipv6 addresses of the nodes on the link of n that reply to an all-nodes echo request.
+// Sweeping an ipv6 subnet by enumerating it is infeasible so the replies also populate the neighbor table
+// which the ipv6 addresses are later merged from.
+func discoverIPv6(ctx context.Context, n network) []net.IP {
+	ips, _ := ping.Multicast(ctx, n.iface, ipv6Timeout)
+	return ips
+}
+
+// addIPv6 merges the ipv6 addresses of the neighbors on the link of n into the devices with the same hardware address.
+// Neighbors that don't share a hardware address with any device are ipv6-only and are added as new devices.
+func addIPv6(_ context.Context, n network, devices []Device, neighbors []neighbor.Entry, replied []net.IP) []Device {
+	// The current device has no neighbor entry for its own addresses.
+	if addrs, err := n.iface.Addrs(); err == nil {
+		for i := range devices {
+			if devices[i].Kind != DeviceKindCurrent {
+				continue
+			}
+			for _, addr := range addrs {
+				if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() == nil {
+					devices[i].addIPv6(ipNet.IP)
+				}
+			}
+		}
+	}
+
+	for _, entry := range neighbors {
+		if entry.IP.To4() != nil || entry.Interface != n.iface.Name || len(entry.MAC) == 0 {
+			continue
+		}
+		// Multicast groups the host joined have neighbor entries with multicast hardware addresses(33:33:...)
+		// but they aren't devices.
+		if !entry.IP.IsGlobalUnicast() && !entry.IP.IsLinkLocalUnicast() || entry.MAC[0]&1 != 0 {
+			continue
+		}
+
+		mac := entry.MAC.String()
+		if i := slices.IndexFunc(devices, func(d Device) bool { return d.MAC == mac }); i != -1 {
+			devices[i].addIPv6(entry.IP)
+			continue
+		}
+
+		devices = append(devices, *n.attach(Device{
+			Kind:     DeviceKindPeer,
+			Hostname: resolve.Hostname(entry.IP),
+			MAC:      mac,
+			Vendor:   oui.Vendor(entry.MAC),
+			Up:       slices.ContainsFunc(replied, entry.IP.Equal),
+			IPv6:     []net.IP{entry.IP},
+		}))
+	}
+
+	// ipv6-only devices are identified by their most widely scoped address.
+	for i := range devices {
+		if devices[i].LocalIP == nil && len(devices[i].IPv6) > 0 {
+			devices[i].LocalIP = devices[i].IPv6[0]
+		}
+	}
+	return devices
+}
+
+// addIPv6 adds ip to the ipv6 addresses of d ordered by scope(global, unique local and then link-local).
+func (d *Device) addIPv6(ip net.IP) {
+	if slices.ContainsFunc(d.IPv6, ip.Equal) {
+		return
+	}
+	d.IPv6 = append(d.IPv6, ip)
+	sort.SliceStable(d.IPv6, func(i, j int) bool {
+		return ipv6Scope(d.IPv6[i]) < ipv6Scope(d.IPv6[j])
+	})
+}
+
+func ipv6Scope(ip net.IP) int {
+	switch {
+	case ip.IsLinkLocalUnicast():
+		return 2
+	case ula.Contains(ip):
+		return 1
+	}
+	return 0
+}
@@ -4,23 +4,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"net"
-	"net/http"
-	"strings"
 	"sync"
 	"time"
 
 	gw "github.com/jackpal/gateway"
-	goping "github.com/tatsushid/go-fastping"
 
+	"github.com/fuskovic/networker/v3/internal/arp"
+	"github.com/fuskovic/networker/v3/internal/neighbor"
+	"github.com/fuskovic/networker/v3/internal/osfp"
+	"github.com/fuskovic/networker/v3/internal/oui"
+	"github.com/fuskovic/networker/v3/internal/ping"
+	"github.com/fuskovic/networker/v3/internal/publicip"
 	"github.com/fuskovic/networker/v3/internal/resolve"
+	"github.com/fuskovic/networker/v3/internal/ssdp"
 )
 
 const (
 	notAvailable = "N/A"
-	icmpIpv4     = "ip4:icmp"
-	icmpIpv6     = "ip6:ipv6-icmp"
 )
 
 const (
@@ -28,65 +29,288 @@ const (
 	DeviceKindRouter  Kind = "router"
 	DeviceKindCurrent Kind = "current-device"
 	DeviceKindPeer    Kind = "peer"
+
+	// Peers are classified as one of the following kinds by the default classification rules.
+	DeviceKindPrinter     Kind = "printer"
+	DeviceKindCamera      Kind = "camera"
+	DeviceKindNAS         Kind = "nas"
+	DeviceKindPhone       Kind = "phone"
+	DeviceKindTV          Kind = "tv"
+	DeviceKindIoT         Kind = "iot"
+	DeviceKindServer      Kind = "server"
+	DeviceKindWorkstation Kind = "workstation"
+)
+
+const (
+	// MethodICMP probes hosts with icmp echo requests.
+	MethodICMP Method = "icmp"
+	// MethodARP probes hosts on the local segment with arp requests.
+	MethodARP Method = "arp"
+)
+
+// arpTimeout is how long to wait for arp replies after the last request was sent.
+const arpTimeout = 2 * time.Second
+
+const (
+	// pingTimeout is how long to wait for each echo reply.
+	pingTimeout = time.Second
+	// pingInterval is the time between consecutive echo requests sent to the same device.
+	pingInterval = 200 * time.Millisecond
 )
 
+// sweepWorkers is how many hosts are resolved and probed at once.
+const sweepWorkers = ping.DefaultWorkers
+
+// MaxHosts is the largest number of addresses that will be swept in a single subnet.
+const MaxHosts = 4096
+
 type Kind string
 
+// Method is a host discovery method.
+type Method string
+
+// Options configures how devices on the local network are discovered.
+type Options struct {
+	// CIDR overrides the subnet of the interface that owns the local ip.
+	CIDR string
+	// Interface is the name of the interface to discover devices on.
+	Interface string
+	// AllInterfaces discovers devices on every network the host is attached to.
+	AllInterfaces bool
+	// PublicIP resolves the remote ip of the current device. The lookup is skipped if it's nil and
+	// the remote ip is left empty with a warning if it fails.
+	PublicIP publicip.Resolver
+	// Method is how hosts are probed to determine whether or not they're up. Defaults to MethodICMP.
+	Method Method
+	// MDNS resolves hostnames and advertised services using multicast dns.
+	MDNS bool
+	// SSDP identifies UPnP devices using the simple service discovery protocol.
+	SSDP bool
+	// NetBIOS resolves hostnames and workgroups using netbios node status requests.
+	NetBIOS bool
+	// LLMNR resolves hostnames using link-local multicast name resolution.
+	LLMNR bool
+	// OS guesses the operating system of each device from the ttl of its echo replies, tcp characteristics and open ports.
+	// Every device that is present is probed with connections to a few common tcp ports.
+	// Classification rules that match on ports or os only apply when it's set.
+	OS bool
+	// IPv6 discovers the ipv6 addresses of devices using an all-nodes echo request and the neighbor table.
+	IPv6 bool
+	// Count is how many echo requests are sent to each device to measure its round-trip time and loss. Defaults to 1.
+	Count int
+	// Ping is how devices are pinged when they're discovered with MethodICMP. It's detected if empty.
+	Ping ping.Method
+	// OnWarning is called with problems that don't stop devices from being listed, like networks that were skipped.
+	// Warnings are discarded if it's nil.
+	OnWarning func(error)
+}
+
 type Device struct {
-	Kind     Kind   `json:"kind" table:"KIND"`
-	Hostname string `json:"hostname" table:"HOSTNAME"`
-	LocalIP  net.IP `json:"local_ip" table:"LOCAL_IP"`
-	RemoteIP net.IP `json:"remote_ip,omitempty" table:"REMOTE_IP"`
-	Up       bool   `json:"up" yaml:"up" table:"UP"`
+	Kind           Kind        `json:"kind" table:"KIND"`
+	Label          string      `json:"label,omitempty" table:"LABEL"`
+	Hostname       string      `json:"hostname" table:"HOSTNAME"`
+	HostnameSource NameSource  `json:"hostname_source,omitempty" table:"SOURCE"`
+	LocalIP        net.IP      `json:"local_ip" table:"LOCAL_IP"`
+	IPv6           []net.IP    `json:"ipv6,omitempty" yaml:"ipv6,omitempty" table:"IPV6"`
+	RemoteIP       net.IP      `json:"remote_ip,omitempty" table:"REMOTE_IP"`
+	Up             bool        `json:"up" yaml:"up" table:"UP"`
+	Interface      string      `json:"interface" table:"INTERFACE"`
+	Subnet         string      `json:"subnet" table:"SUBNET"`
+	MAC            string      `json:"mac,omitempty" table:"MAC"`
+	Vendor         string      `json:"vendor,omitempty" table:"VENDOR"`
+	Services       []string    `json:"services,omitempty" table:"SERVICES"`
+	Tags           []string    `json:"tags,omitempty" table:"TAGS"`
+	Owner          string      `json:"owner,omitempty" table:"-"`
+	Workgroup      string      `json:"workgroup,omitempty" table:"-"`
+	OS             *osfp.Guess `json:"os,omitempty" yaml:"os,omitempty" table:"OS"`
+	// Ping summarizes the echo requests sent to the device. It's nil unless the device was probed with icmp.
+	Ping *ping.Statistics `json:"ping,omitempty" yaml:"ping,omitempty" table:"PING"`
+	// OpenPorts are the ports probed to fingerprint the device that accepted connections.
+	OpenPorts []int `json:"open_ports,omitempty" yaml:"open_ports,omitempty" table:"-"`
+	// UPnP is the description of the device if it responded to an ssdp search.
+	UPnP *ssdp.Device `json:"upnp,omitempty" yaml:"upnp,omitempty" table:"-"`
+}
+
+// Present reports whether d was found on the network because it either responded to a probe or its hardware address is known.
+func (d Device) Present() bool {
+	return d.Up || d.MAC != ""
 }
 
 // Devices lists all of the devices on the local network.
-func Devices(ctx context.Context) ([]Device, error) {
-	cidr, err := getCIDR(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get cidr: %w", err)
+func Devices(ctx context.Context, opts Options) ([]Device, error) {
+	switch opts.Method {
+	case MethodICMP, MethodARP, "":
+	default:
+		return nil, fmt.Errorf("unsupported discovery method %q", opts.Method)
 	}
 
-	router, err := getRouter(ctx)
+	networks, err := getNetworks(ctx, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get router: %w", err)
+		return nil, fmt.Errorf("failed to get networks: %w", err)
 	}
 
-	hostIPs, err := getHosts(ctx, cidr, router)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get hosts: %w", err)
+	// Networks without a default route(e.g. air-gapped networks) have no router.
+	router, _ := getRouter(ctx)
+
+	var remoteIP net.IP
+	if opts.PublicIP != nil {
+		// Networks without internet access(e.g. air-gapped networks) are still listed without the remote ip.
+		if remoteIP, err = opts.PublicIP.PublicIP(ctx); err != nil {
+			opts.warn(fmt.Errorf("failed to get remote ip of current device: %w", err))
+		}
+	}
+
+	pinger := ping.NewPinger(opts.Ping, sweepWorkers)
+	pinger.Count = max(opts.Count, 1)
+	pinger.Interval = pingInterval
+	pinger.Timeout = pingTimeout
+
+	var (
+		devices []Device
+		swept   []network
+	)
+	for _, n := range networks {
+		networkDevices, err := getNetworkDevices(ctx, n, router, remoteIP, pinger, opts)
+		if err != nil {
+			// Networks that can't be swept(e.g. docker bridges too big to sweep or vpn tunnels without arp)
+			// are skipped unless they were picked explicitly.
+			if !opts.AllInterfaces {
+				return nil, fmt.Errorf("failed to list devices on %s: %w", n.iface.Name, err)
+			}
+			opts.warn(fmt.Errorf("skipping %s on %s: %w", n.subnet, n.iface.Name, err))
+			continue
+		}
+		devices = append(devices, networkDevices...)
+		swept = append(swept, n)
+	}
+	if len(swept) == 0 {
+		return nil, errors.New("none of the networks the host is attached to could be swept")
 	}
+	networks = swept
 
-	currentDevice, err := getCurrentDevice(ctx)
+	discover(ctx, networks, devices, opts).apply(devices)
+	if opts.OS {
+		fingerprint(ctx, devices)
+	}
+	classifyDevices(devices)
+	return devices, nil
+}
+
+func (opts Options) warn(err error) {
+	if opts.OnWarning != nil {
+		opts.OnWarning(err)
+	}
+}
+
+// getNetworkDevices sweeps the subnet of n for devices.
+func getNetworkDevices(ctx context.Context, n network, router *Device, remoteIP net.IP, pinger *ping.Pinger, opts Options) ([]Device, error) {
+	var networkRouter *Device
+	if router != nil && n.subnet.Contains(router.LocalIP) {
+		networkRouter = n.attach(*router)
+	}
+
+	hostIPs, err := getHosts(ctx, n.subnet.String(), networkRouter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get current device: %w", err)
+		return nil, fmt.Errorf("failed to get hosts: %w", err)
 	}
 
+	currentDevice := n.attach(*getCurrentDevice(ctx, n.localIP, remoteIP))
 	hostIPs = removeIP(currentDevice.LocalIP.String(), hostIPs)
 
+	hostIPs = dedupe(hostIPs)
+
 	var (
-		devices = []Device{*router, *currentDevice}
+		devices = []Device{*currentDevice}
 		wg      = sync.WaitGroup{}
 		mutex   = sync.Mutex{}
+		probe   = func(d *Device) {
+			stats := pinger.Ping(ctx, d.LocalIP)
+			d.Up = d.Up || stats.Received > 0
+			d.Ping = &stats
+		}
+		// Hardware addresses learned while probing take precedence over the neighbor table.
+		neighbors []neighbor.Entry
 	)
 
-	for _, hostIP := range dedupe(hostIPs) {
+	switch opts.Method {
+	case MethodARP:
+		neighbors, err = arp.Sweep(ctx, &n.iface, n.localIP, parseIPs(hostIPs), arpTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sweep %s with arp: %w", n.subnet, err)
+		}
+		probe = func(d *Device) {
+			d.Up = d.Up || neighbor.Lookup(neighbors, d.LocalIP) != nil
+		}
+	}
+
+	if networkRouter != nil {
+		// The router is always up but it's probed anyway to measure its round-trip time.
 		wg.Add(1)
-		go func(ip string) {
+		go func() {
 			defer wg.Done()
+			probe(networkRouter)
+		}()
+	}
 
-			device, err := getDevice(ctx, ip)
-			if err != nil || device == nil {
-				return
-			}
-			device.Up = ping(ip)
+	var replied []net.IP
+	if opts.IPv6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			replied = discoverIPv6(ctx, n)
+		}()
+	}
 
-			mutex.Lock()
-			devices = append(devices, *device)
-			mutex.Unlock()
-		}(hostIP)
+	hosts := make(chan string)
+	for i := 0; i < min(sweepWorkers, len(hostIPs)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ip := range hosts {
+				device, err := getDevice(ctx, ip)
+				if err != nil || device == nil {
+					continue
+				}
+				probe(device)
+
+				mutex.Lock()
+				devices = append(devices, *n.attach(*device))
+				mutex.Unlock()
+			}
+		}()
+	}
+	for _, hostIP := range hostIPs {
+		hosts <- hostIP
 	}
+	close(hosts)
 	wg.Wait()
+
+	if networkRouter != nil {
+		devices = append([]Device{*networkRouter}, devices...)
+	}
+
+	// The ping sweep populates the neighbor table with every device that responded to arp.
+	// Not every platform exposes the neighbor table so the hardware addresses are best-effort.
+	if table, err := neighbor.Table(); err == nil {
+		neighbors = append(neighbors, table...)
+	}
+	for i := range devices {
+		var mac net.HardwareAddr
+		if devices[i].Kind == DeviceKindCurrent {
+			mac = n.iface.HardwareAddr
+		} else if entry := neighbor.Lookup(neighbors, devices[i].LocalIP); entry != nil {
+			mac = entry.MAC
+			devices[i].Interface = entry.Interface
+		}
+		if len(mac) > 0 {
+			devices[i].MAC = mac.String()
+			devices[i].Vendor = oui.Vendor(mac)
+		}
+	}
+
+	if opts.IPv6 {
+		devices = addIPv6(ctx, n, devices, neighbors, replied)
+	}
 	return devices, nil
 }
 
@@ -103,24 +327,14 @@ func getDevice(_ context.Context, ip string) (*Device, error) {
 	}, nil
 }
 
-func getCurrentDevice(_ context.Context) (*Device, error) {
-	localIP, err := getCurrentDeviceLocalIP()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get local ip of current device: %w", err)
-	}
-
-	remoteIP, err := getCurrentDeviceRemoteIP()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get remote ip of current device: %w", err)
-	}
-
+func getCurrentDevice(_ context.Context, localIP, remoteIP net.IP) *Device {
 	return &Device{
 		LocalIP:  localIP,
 		RemoteIP: remoteIP,
 		Hostname: resolve.Hostname(localIP),
 		Kind:     DeviceKindCurrent,
 		Up:       true,
-	}, nil
+	}
 }
 
 func getRouter(_ context.Context) (*Device, error) {
@@ -137,76 +351,18 @@ func getRouter(_ context.Context) (*Device, error) {
 	}, nil
 }
 
-func getCIDR(_ context.Context) (string, error) {
-	localIP, err := getCurrentDeviceLocalIP()
-	if err != nil {
-		return "", fmt.Errorf("failed to get local ip: %w", err)
-	}
-	return fmt.Sprintf("%s/24", localIP.Mask(localIP.DefaultMask())), nil
-}
-
-func getHosts(_ context.Context, cidr string, router *Device) ([]string, error) {
-	ip, network, err := net.ParseCIDR(cidr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse cidr %s: %w", cidr, err)
-	}
-
-	inc := func(ip net.IP) {
-		for j := len(ip) - 1; j >= 0; j-- {
-			ip[j]++
-			if ip[j] > 0 {
-				break
-			}
-		}
-	}
-
-	var ips []string
-	for ip := ip.Mask(network.Mask); network.Contains(ip); inc(ip) {
-		if ip.String() == router.LocalIP.String() {
-			continue
-		}
-		ips = append(ips, ip.String())
-	}
-	return ips[1 : len(ips)-1], nil
-}
-
+// getCurrentDeviceLocalIP returns the ipv4 address of the interface that owns the default route.
+// If there is no default route, the first ipv4 address of an interface that is up is used instead.
 func getCurrentDeviceLocalIP() (net.IP, error) {
-	c, err := net.Dial("udp", "8.8.8.8:80")
-	if err != nil {
-		return nil, fmt.Errorf("failed to dial google dns : %w", err)
-	}
-	defer c.Close()
-
-	localAddr, ok := c.LocalAddr().(*net.UDPAddr)
-	if !ok {
-		return nil, errors.New("failed to resolve local IP")
-	}
-	return localAddr.IP, nil
-}
-
-func getCurrentDeviceRemoteIP() (net.IP, error) {
-	r, err := http.Get("http://myexternalip.com/raw")
-	if err != nil {
-		return nil, err
+	if ip, err := gw.DiscoverInterface(); err == nil {
+		return ip, nil
 	}
-	defer r.Body.Close()
 
-	b, err := io.ReadAll(r.Body)
+	networks, err := getAllNetworks()
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to resolve local ip: %w", err)
 	}
-
-	var remoteIP net.IP
-	ipAddr := string(b)
-	if strings.Contains(ipAddr, ":") {
-		remoteIP = net.ParseIP(ipAddr).To16()
-	} else {
-		remoteIP = net.ParseIP(ipAddr).To4()
-	}
-	if remoteIP == nil {
-		return nil, fmt.Errorf("failed to get resolve ip %q as ipv4", b)
-	}
-	return remoteIP, nil
+	return networks[0].localIP, nil
 }
 
 func dedupe(hosts []string) []string {
@@ -222,6 +378,16 @@ func dedupe(hosts []string) []string {
 	return filteredHosts
 }
 
+func parseIPs(hosts []string) []net.IP {
+	var ips []net.IP
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
 func removeIP(ip string, hosts []string) []string {
 	var filteredHosts []string
 	for _, host := range hosts {
@@ -232,28 +398,3 @@ func removeIP(ip string, hosts []string) []string {
 	}
 	return filteredHosts
 }
-
-func ping(ip string) bool {
-	p := goping.NewPinger()
-	p.Network("udp")
-
-	netProto := icmpIpv4
-	if strings.Contains(ip, ":") {
-		netProto = icmpIpv6
-	}
-
-	addr, err := net.ResolveIPAddr(netProto, ip)
-	if err != nil {
-		return false
-	}
-
-	p.AddIPAddr(addr)
-	p.MaxRTT = time.Second
-
-	var up bool
-	p.OnRecv = func(addr *net.IPAddr, t time.Duration) {
-		up = true
-	}
-	p.Run()
-	return up
-}
@@ -2,9 +2,13 @@ package list
 
 import (
 	"context"
+	"net"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/fuskovic/networker/v3/internal/neighbor"
+	"github.com/fuskovic/networker/v3/internal/netbios"
 )
 
 func TestListDevices(t *testing.T) {
@@ -17,18 +21,112 @@ func TestListDevices(t *testing.T) {
 		t.Run("find current device in list of devices", func(t *testing.T) {
 			t.Parallel()
 			ctx := context.Background()
-			currentDevice, err := getCurrentDevice(ctx)
+			localIP, err := getCurrentDeviceLocalIP()
 			require.NoError(t, err)
-			devices, err := Devices(ctx)
+			devices, err := Devices(ctx, Options{})
 			require.NoError(t, err)
 			var foundDevice bool
 			for _, d := range devices {
-				if d.LocalIP.String() == currentDevice.LocalIP.String() {
+				if d.LocalIP.String() == localIP.String() {
 					foundDevice = true
 				}
 			}
 			require.True(t, foundDevice)
 		})
+		t.Run("get hosts excludes network, broadcast and router addresses", func(t *testing.T) {
+			router := &Device{LocalIP: net.ParseIP("10.0.4.1")}
+			hosts, err := getHosts(context.Background(), "10.0.4.0/22", router)
+			require.NoError(t, err)
+			require.Len(t, hosts, 1021)
+			require.Equal(t, "10.0.4.2", hosts[0])
+			require.Equal(t, "10.0.7.254", hosts[len(hosts)-1])
+		})
+		t.Run("get local network of loopback address", func(t *testing.T) {
+			n, err := getLocalNetwork(net.ParseIP("127.0.0.1"))
+			require.NoError(t, err)
+			require.Equal(t, "127.0.0.0/8", n.subnet.String())
+			require.Equal(t, "127.0.0.0/8", n.attach(Device{}).Subnet)
+		})
+		t.Run("get hosts of point-to-point links", func(t *testing.T) {
+			hosts, err := getHosts(context.Background(), "192.168.7.4/30", nil)
+			require.NoError(t, err)
+			require.Equal(t, []string{"192.168.7.5", "192.168.7.6"}, hosts)
+
+			hosts, err = getHosts(context.Background(), "192.168.7.4/31", nil)
+			require.NoError(t, err)
+			require.Equal(t, []string{"192.168.7.4", "192.168.7.5"}, hosts)
+		})
+		t.Run("apply hostnames in order of name source precedence", func(t *testing.T) {
+			devices := []Device{
+				{Hostname: "router.lan.", LocalIP: net.ParseIP("10.0.0.1")},
+				{Hostname: notAvailable, LocalIP: net.ParseIP("10.0.0.2")},
+				{Hostname: notAvailable, LocalIP: net.ParseIP("10.0.0.3")},
+			}
+			discovery{
+				mdnsHostnames: map[string]string{"10.0.0.2": "printer.local."},
+				netbiosStatuses: map[string]netbios.Status{
+					"10.0.0.1": {Name: "ROUTER", Workgroup: "HOME"},
+					"10.0.0.2": {Name: "PRINTER"},
+					"10.0.0.3": {Name: "DESKTOP", Workgroup: "HOME", MAC: net.HardwareAddr{0, 1, 2, 3, 4, 5}},
+				},
+				llmnrHostnames: map[string]string{"10.0.0.3": "desktop."},
+			}.apply(devices)
+
+			require.Equal(t, "router.lan.", devices[0].Hostname)
+			require.Equal(t, NameSourceDNS, devices[0].HostnameSource)
+			require.Equal(t, "HOME", devices[0].Workgroup)
+			require.Equal(t, "printer.local.", devices[1].Hostname)
+			require.Equal(t, NameSourceMDNS, devices[1].HostnameSource)
+			require.Equal(t, "DESKTOP", devices[2].Hostname)
+			require.Equal(t, NameSourceNetBIOS, devices[2].HostnameSource)
+			require.Equal(t, "00:01:02:03:04:05", devices[2].MAC)
+			require.Equal(t, "3COM", devices[2].Vendor)
+		})
+		t.Run("merge ipv6 neighbors into devices with the same hardware address", func(t *testing.T) {
+			n := network{iface: net.Interface{Name: "eth9"}, subnet: &net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(24, 32)}}
+			mac := func(s string) net.HardwareAddr {
+				m, _ := net.ParseMAC(s)
+				return m
+			}
+
+			devices := addIPv6(context.Background(), n,
+				[]Device{{Kind: DeviceKindPeer, LocalIP: net.ParseIP("10.0.0.5"), MAC: "00:11:32:aa:bb:cc"}},
+				[]neighbor.Entry{
+					{IP: net.ParseIP("10.0.0.5"), MAC: mac("00:11:32:aa:bb:cc"), Interface: "eth9"},
+					{IP: net.ParseIP("fe80::211:32ff:feaa:bbcc"), MAC: mac("00:11:32:aa:bb:cc"), Interface: "eth9"},
+					{IP: net.ParseIP("2001:db8::5"), MAC: mac("00:11:32:aa:bb:cc"), Interface: "eth9"},
+					{IP: net.ParseIP("fd00::9"), MAC: mac("b8:27:eb:12:34:56"), Interface: "eth9"},
+					{IP: net.ParseIP("fd00::10"), MAC: mac("b8:27:eb:65:43:21"), Interface: "eth10"},
+				},
+				[]net.IP{net.ParseIP("fd00::9")},
+			)
+
+			require.Len(t, devices, 2)
+			require.Equal(t, []net.IP{net.ParseIP("2001:db8::5"), net.ParseIP("fe80::211:32ff:feaa:bbcc")}, devices[0].IPv6)
+			require.Equal(t, "fd00::9", devices[1].LocalIP.String())
+			require.Equal(t, "b8:27:eb:12:34:56", devices[1].MAC)
+			require.True(t, devices[1].Up)
+		})
+		t.Run("skip multicast ipv6 neighbors", func(t *testing.T) {
+			n := network{iface: net.Interface{Name: "eth9"}, subnet: &net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(24, 32)}}
+			mac := func(s string) net.HardwareAddr {
+				m, _ := net.ParseMAC(s)
+				return m
+			}
+
+			devices := addIPv6(context.Background(), n, nil,
+				[]neighbor.Entry{
+					{IP: net.ParseIP("ff02::1"), MAC: mac("33:33:00:00:00:01"), Interface: "eth9"},
+					{IP: net.ParseIP("ff02::1:ff00:1"), MAC: mac("33:33:ff:00:00:01"), Interface: "eth9"},
+					{IP: net.ParseIP("fe80::1"), MAC: mac("33:33:00:00:00:16"), Interface: "eth9"},
+					{IP: net.ParseIP("fe80::2"), MAC: mac("b8:27:eb:12:34:56"), Interface: "eth9"},
+				},
+				nil,
+			)
+
+			require.Len(t, devices, 1)
+			require.Equal(t, "fe80::2", devices[0].LocalIP.String())
+		})
 	})
 	t.Run("ShouldFail", func(t *testing.T) {
 		t.Run("get device using invalid ip", func(t *testing.T) {
@@ -36,6 +134,21 @@ func TestListDevices(t *testing.T) {
 			require.Nil(t, d)
 			require.Error(t, err)
 		})
+		t.Run("get networks of unknown interface", func(t *testing.T) {
+			networks, err := getNetworks(context.Background(), Options{Interface: "does-not-exist"})
+			require.Nil(t, networks)
+			require.Error(t, err)
+		})
+		t.Run("get networks of all interfaces with a cidr", func(t *testing.T) {
+			networks, err := getNetworks(context.Background(), Options{AllInterfaces: true, CIDR: "10.0.0.0/24"})
+			require.Nil(t, networks)
+			require.Error(t, err)
+		})
+		t.Run("get hosts using a subnet that is too large", func(t *testing.T) {
+			hosts, err := getHosts(context.Background(), "10.0.0.0/16", nil)
+			require.Nil(t, hosts)
+			require.Error(t, err)
+		})
 		t.Run("get hosts using invalid CIDR", func(t *testing.T) {
 			hosts, err := getHosts(context.Background(), "invalid", nil)
 			require.Nil(t, hosts)
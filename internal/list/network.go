@@ -0,0 +1,194 @@
+package list
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+)
+
+// network is a subnet the current device is attached to.
+type network struct {
+	iface   net.Interface
+	localIP net.IP
+	subnet  *net.IPNet
+}
+
+// attach records that d was found on n.
+func (n network) attach(d Device) *Device {
+	d.Interface = n.iface.Name
+	d.Subnet = n.subnet.String()
+	return &d
+}
+
+// interfaces returns the unique interfaces of networks.
+func interfaces(networks []network) []net.Interface {
+	var ifaces []net.Interface
+	for _, n := range networks {
+		if !slices.ContainsFunc(ifaces, func(iface net.Interface) bool { return iface.Index == n.iface.Index }) {
+			ifaces = append(ifaces, n.iface)
+		}
+	}
+	return ifaces
+}
+
+// getNetworks returns the networks that should be swept for devices.
+func getNetworks(_ context.Context, opts Options) ([]network, error) {
+	if opts.AllInterfaces {
+		if opts.CIDR != "" {
+			return nil, errors.New("a cidr cannot be used when listing devices on all interfaces")
+		}
+		return getAllNetworks()
+	}
+
+	var (
+		n   *network
+		err error
+	)
+	if opts.Interface != "" {
+		n, err = getInterfaceNetwork(opts.Interface)
+	} else {
+		var localIP net.IP
+		localIP, err = getCurrentDeviceLocalIP()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get local ip: %w", err)
+		}
+		n, err = getLocalNetwork(localIP)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.CIDR != "" {
+		_, subnet, err := net.ParseCIDR(opts.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cidr %s: %w", opts.CIDR, err)
+		}
+		n.subnet = subnet
+	}
+	return []network{*n}, nil
+}
+
+// getAllNetworks returns the ipv4 networks of every interface that is up.
+func getAllNetworks() ([]network, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interfaces: %w", err)
+	}
+
+	var networks []network
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		networks = append(networks, interfaceNetworks(iface)...)
+	}
+
+	if len(networks) == 0 {
+		return nil, errors.New("no interfaces with an ipv4 address are up")
+	}
+	return networks, nil
+}
+
+// getInterfaceNetwork returns the first ipv4 network of the named interface.
+func getInterfaceNetwork(name string) (*network, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find interface %q: %w", name, err)
+	}
+
+	networks := interfaceNetworks(*iface)
+	if len(networks) == 0 {
+		return nil, fmt.Errorf("interface %q has no ipv4 address", name)
+	}
+	return &networks[0], nil
+}
+
+// getLocalNetwork returns the network of the interface that owns ip.
+func getLocalNetwork(ip net.IP) (*network, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interfaces: %w", err)
+	}
+
+	for _, iface := range ifaces {
+		for _, n := range interfaceNetworks(iface) {
+			if n.localIP.Equal(ip) {
+				return &n, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no interface found with address %s", ip)
+}
+
+// interfaceNetworks returns a network for each ipv4 address assigned to iface.
+func interfaceNetworks(iface net.Interface) []network {
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil
+	}
+
+	var networks []network
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.To4() == nil {
+			continue
+		}
+		networks = append(networks,
+			network{
+				iface:   iface,
+				localIP: ipNet.IP.To4(),
+				subnet: &net.IPNet{
+					IP:   ipNet.IP.To4().Mask(ipNet.Mask),
+					Mask: ipNet.Mask,
+				},
+			},
+		)
+	}
+	return networks
+}
+
+func getHosts(_ context.Context, cidr string, router *Device) ([]string, error) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cidr %s: %w", cidr, err)
+	}
+
+	ones, bits := network.Mask.Size()
+	if hostBits := bits - ones; hostBits > 30 || 1<<hostBits > MaxHosts {
+		return nil, fmt.Errorf("subnet %s exceeds the limit of %d hosts", network, MaxHosts)
+	}
+
+	inc := func(ip net.IP) {
+		for j := len(ip) - 1; j >= 0; j-- {
+			ip[j]++
+			if ip[j] > 0 {
+				break
+			}
+		}
+	}
+
+	var ips []string
+	for ip := network.IP.Mask(network.Mask); network.Contains(ip); inc(ip) {
+		if router != nil && ip.Equal(router.LocalIP) {
+			continue
+		}
+		ips = append(ips, ip.String())
+	}
+
+	// Point-to-point links(/31) and single hosts(/32) have no network or broadcast address.
+	if bits-ones < 2 {
+		return ips, nil
+	}
+	return removeIP(broadcast(network).String(), removeIP(network.IP.String(), ips)), nil
+}
+
+// broadcast returns the last address in network.
+func broadcast(network *net.IPNet) net.IP {
+	ip := make(net.IP, len(network.IP))
+	for i := range network.IP {
+		ip[i] = network.IP[i] | ^network.Mask[i]
+	}
+	return ip
+}
@@ -0,0 +1,114 @@
+package llmnr
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/fuskovic/networker/v3/internal/resolve"
+)
+
+const port = 5355
+
+// LookupAddr resolves the hostnames of ips by sending a reverse LLMNR query directly to each of them(RFC 4795 section 2.4).
+// The returned map is keyed by the string representation of each ip that was resolved.
+// Responses are collected until timeout elapses or ctx is done.
+func LookupAddr(ctx context.Context, timeout time.Duration, ips []net.IP) (map[string]string, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for llmnr responses: %w", err)
+	}
+	defer conn.Close()
+
+	queries := make(map[uint16]string)
+	for i, ip := range ips {
+		id := uint16(i + 1)
+		b, err := query(id, resolve.ReverseName(ip))
+		if err != nil {
+			continue
+		}
+		queries[id] = ip.String()
+		// hosts on networks we have no route to are skipped
+		_, _ = conn.WriteToUDP(b, &net.UDPAddr{IP: ip, Port: port})
+	}
+
+	var (
+		hostnames = make(map[string]string)
+		buf       = make([]byte, 1500)
+		deadline  = time.Now().Add(timeout)
+	)
+
+	for time.Now().Before(deadline) && len(hostnames) < len(queries) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		_ = conn.SetReadDeadline(minTime(deadline, time.Now().Add(100*time.Millisecond)))
+		n, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			// deadlines and icmp port unreachable errors from hosts
+			// that don't run an llmnr responder are expected.
+			continue
+		}
+
+		id, hostname, err := parseResponse(buf[:n])
+		if err != nil {
+			continue
+		}
+
+		// only accept answers from the host that was asked
+		if ip, ok := queries[id]; ok && ip == addr.IP.String() {
+			hostnames[ip] = hostname
+		}
+	}
+	return hostnames, nil
+}
+
+func query(id uint16, name string) ([]byte, error) {
+	n, err := dnsmessage.NewName(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name %q: %w", name, err)
+	}
+
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: id},
+		Questions: []dnsmessage.Question{
+			{
+				Name:  n,
+				Type:  dnsmessage.TypePTR,
+				Class: dnsmessage.ClassINET,
+			},
+		},
+	}
+	return msg.Pack()
+}
+
+// parseResponse returns the id and the hostname of the first PTR answer of an LLMNR response.
+func parseResponse(b []byte) (uint16, string, error) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(b); err != nil {
+		return 0, "", fmt.Errorf("failed to unpack response: %w", err)
+	}
+	if !msg.Header.Response {
+		return 0, "", fmt.Errorf("not a response")
+	}
+
+	for _, answer := range msg.Answers {
+		if ptr, ok := answer.Body.(*dnsmessage.PTRResource); ok {
+			return msg.Header.ID, ptr.PTR.String(), nil
+		}
+	}
+	return 0, "", fmt.Errorf("response has no ptr answer")
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
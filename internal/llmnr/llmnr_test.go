@@ -0,0 +1,55 @@
+package llmnr
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestLLMNR(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("parse ptr response", func(t *testing.T) {
+			id, hostname, err := parseResponse(response(t, 7, "office-pc."))
+			require.NoError(t, err)
+			require.Equal(t, uint16(7), id)
+			require.Equal(t, "office-pc.", hostname)
+		})
+		t.Run("lookup addr of host without responder", func(t *testing.T) {
+			hostnames, err := LookupAddr(context.Background(), 100*time.Millisecond, []net.IP{net.ParseIP("127.0.0.1")})
+			require.NoError(t, err)
+			require.Empty(t, hostnames)
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("parse query as response", func(t *testing.T) {
+			b, err := query(1, "1.0.0.127.in-addr.arpa.")
+			require.NoError(t, err)
+			_, _, err = parseResponse(b)
+			require.Error(t, err)
+		})
+	})
+}
+
+func response(t *testing.T, id uint16, hostname string) []byte {
+	t.Helper()
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: id, Response: true},
+		Answers: []dnsmessage.Resource{
+			{
+				Header: dnsmessage.ResourceHeader{
+					Name:  dnsmessage.MustNewName("20.1.168.192.in-addr.arpa."),
+					Class: dnsmessage.ClassINET,
+				},
+				Body: &dnsmessage.PTRResource{PTR: dnsmessage.MustNewName(hostname)},
+			},
+		},
+	}
+	b, err := msg.Pack()
+	require.NoError(t, err)
+	return b
+}
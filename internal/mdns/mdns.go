@@ -0,0 +1,303 @@
+package mdns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	"golang.org/x/net/ipv4"
+
+	"github.com/fuskovic/networker/v3/internal/resolve"
+)
+
+const (
+	// servicesName is the meta-query name that enumerates every service type advertised on the network.
+	servicesName = "_services._dns-sd._udp.local."
+	localDomain  = "local."
+)
+
+var group = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// Service is a service instance advertised over DNS-SD.
+type Service struct {
+	Instance string   `json:"instance" yaml:"instance" table:"INSTANCE"`
+	Type     string   `json:"type" yaml:"type" table:"TYPE"`
+	Host     string   `json:"host" yaml:"host" table:"HOST"`
+	IPs      []net.IP `json:"ips" yaml:"ips" table:"IPS"`
+	Port     int      `json:"port" yaml:"port" table:"PORT"`
+	Text     []string `json:"txt,omitempty" yaml:"txt,omitempty" table:"-"`
+}
+
+// Browse discovers the services advertised on the local network by querying
+// for every service type and then every instance of each service type.
+// Responses are collected until timeout elapses or ctx is done.
+// Queries are sent on each of ifaces or the system default multicast interface if none are provided.
+func Browse(ctx context.Context, timeout time.Duration, ifaces ...net.Interface) ([]Service, error) {
+	c, err := newClient(ifaces)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+
+	b := browser{
+		query:     c.query,
+		types:     make(map[string]bool),
+		instances: make(map[string]*Service),
+		hosts:     make(map[string][]net.IP),
+	}
+
+	if err := c.query(servicesName, dnsmessage.TypePTR); err != nil {
+		return nil, err
+	}
+
+	if err := c.receive(ctx, time.Now().Add(timeout), b.handle); err != nil {
+		return nil, err
+	}
+	return b.services(), nil
+}
+
+// LookupAddr resolves the hostnames of ips using reverse multicast dns queries.
+// The returned map is keyed by the string representation of each ip that was resolved.
+func LookupAddr(ctx context.Context, timeout time.Duration, ips []net.IP, ifaces ...net.Interface) (map[string]string, error) {
+	c, err := newClient(ifaces)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+
+	reverseNames := make(map[string]string)
+	for _, ip := range ips {
+		name := resolve.ReverseName(ip)
+		if name == "" {
+			continue
+		}
+		reverseNames[name] = ip.String()
+		if err := c.query(name, dnsmessage.TypePTR); err != nil {
+			return nil, err
+		}
+	}
+
+	hostnames := make(map[string]string)
+	handle := func(r dnsmessage.Resource) {
+		ptr, ok := r.Body.(*dnsmessage.PTRResource)
+		if !ok {
+			return
+		}
+		if ip, ok := reverseNames[strings.ToLower(r.Header.Name.String())]; ok {
+			hostnames[ip] = ptr.PTR.String()
+		}
+	}
+
+	if err := c.receive(ctx, time.Now().Add(timeout), handle); err != nil {
+		return nil, err
+	}
+	return hostnames, nil
+}
+
+// browser tracks the state of a DNS-SD browse.
+type browser struct {
+	query     func(name string, qtype dnsmessage.Type) error
+	types     map[string]bool
+	instances map[string]*Service
+	hosts     map[string][]net.IP
+}
+
+func (b *browser) handle(r dnsmessage.Resource) {
+	name := strings.ToLower(r.Header.Name.String())
+	switch body := r.Body.(type) {
+	case *dnsmessage.PTRResource:
+		target := body.PTR.String()
+		if name == servicesName {
+			if !b.types[strings.ToLower(target)] {
+				b.types[strings.ToLower(target)] = true
+				_ = b.query(target, dnsmessage.TypePTR)
+			}
+			return
+		}
+
+		if !isServiceType(name) {
+			return
+		}
+
+		key := strings.ToLower(target)
+		if _, ok := b.instances[key]; ok {
+			return
+		}
+		b.instances[key] = &Service{
+			Instance: strings.TrimSuffix(target, "."+r.Header.Name.String()),
+			Type:     strings.TrimSuffix(strings.TrimSuffix(r.Header.Name.String(), "."+localDomain), "."),
+		}
+		_ = b.query(target, dnsmessage.TypeSRV)
+		_ = b.query(target, dnsmessage.TypeTXT)
+	case *dnsmessage.SRVResource:
+		svc, ok := b.instances[name]
+		if !ok {
+			return
+		}
+		svc.Host = body.Target.String()
+		svc.Port = int(body.Port)
+		if _, ok := b.hosts[strings.ToLower(svc.Host)]; !ok {
+			_ = b.query(svc.Host, dnsmessage.TypeA)
+		}
+	case *dnsmessage.TXTResource:
+		if svc, ok := b.instances[name]; ok {
+			svc.Text = body.TXT
+		}
+	case *dnsmessage.AResource:
+		b.addHost(name, net.IP(body.A[:]))
+	case *dnsmessage.AAAAResource:
+		b.addHost(name, net.IP(body.AAAA[:]))
+	}
+}
+
+func (b *browser) addHost(name string, ip net.IP) {
+	for _, known := range b.hosts[name] {
+		if known.Equal(ip) {
+			return
+		}
+	}
+	b.hosts[name] = append(b.hosts[name], ip)
+}
+
+func (b *browser) services() []Service {
+	var services []Service
+	for _, svc := range b.instances {
+		if svc.Host == "" {
+			continue
+		}
+		svc.IPs = b.hosts[strings.ToLower(svc.Host)]
+		services = append(services, *svc)
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Type != services[j].Type {
+			return services[i].Type < services[j].Type
+		}
+		return services[i].Instance < services[j].Instance
+	})
+	return services
+}
+
+// isServiceType reports whether name is a DNS-SD service type(e.g. _ipp._tcp.local.).
+func isServiceType(name string) bool {
+	return name != servicesName && strings.HasPrefix(name, "_") &&
+		(strings.HasSuffix(name, "._tcp."+localDomain) || strings.HasSuffix(name, "._udp."+localDomain))
+}
+
+// client sends one-shot multicast dns queries from an ephemeral port
+// which responders answer with unicast responses(RFC 6762 section 5.1).
+type client struct {
+	conn   *net.UDPConn
+	pc     *ipv4.PacketConn
+	ifaces []net.Interface
+}
+
+func newClient(ifaces []net.Interface) (*client, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero})
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for mdns responses: %w", err)
+	}
+	return &client{
+		conn:   conn,
+		pc:     ipv4.NewPacketConn(conn),
+		ifaces: ifaces,
+	}, nil
+}
+
+func (c *client) close() {
+	c.conn.Close()
+}
+
+// query sends a question for name on each interface of the client.
+func (c *client) query(name string, qtype dnsmessage.Type) error {
+	n, err := dnsmessage.NewName(name)
+	if err != nil {
+		return fmt.Errorf("invalid name %q: %w", name, err)
+	}
+
+	msg := dnsmessage.Message{
+		Questions: []dnsmessage.Question{
+			{
+				Name:  n,
+				Type:  qtype,
+				Class: dnsmessage.ClassINET,
+			},
+		},
+	}
+
+	b, err := msg.Pack()
+	if err != nil {
+		return fmt.Errorf("failed to pack query for %q: %w", name, err)
+	}
+
+	if len(c.ifaces) == 0 {
+		_, err := c.conn.WriteToUDP(b, group)
+		return err
+	}
+
+	var errs []error
+	for i := range c.ifaces {
+		if err := c.pc.SetMulticastInterface(&c.ifaces[i]); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := c.conn.WriteToUDP(b, group); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Queries only need to make it out of one of the interfaces.
+	if len(errs) == len(c.ifaces) {
+		return fmt.Errorf("failed to send query for %q: %w", name, errors.Join(errs...))
+	}
+	return nil
+}
+
+// receive passes every record of every response to handle until the deadline or ctx is done.
+func (c *client) receive(ctx context.Context, deadline time.Time, handle func(dnsmessage.Resource)) error {
+	buf := make([]byte, 9000)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if time.Now().After(deadline) {
+			return nil
+		}
+
+		_ = c.conn.SetReadDeadline(minTime(deadline, time.Now().Add(100*time.Millisecond)))
+		n, _, err := c.conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				continue
+			}
+			return fmt.Errorf("failed to read mdns response: %w", err)
+		}
+
+		var msg dnsmessage.Message
+		if err := msg.Unpack(buf[:n]); err != nil || !msg.Header.Response {
+			continue
+		}
+
+		for _, records := range [][]dnsmessage.Resource{msg.Answers, msg.Additionals} {
+			for _, r := range records {
+				handle(r)
+			}
+		}
+	}
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,75 @@
+package mdns
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestMDNS(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("browse services", func(t *testing.T) {
+			var queries []string
+			b := browser{
+				query: func(name string, qtype dnsmessage.Type) error {
+					queries = append(queries, qtype.String()+" "+name)
+					return nil
+				},
+				types:     make(map[string]bool),
+				instances: make(map[string]*Service),
+				hosts:     make(map[string][]net.IP),
+			}
+
+			for _, r := range []dnsmessage.Resource{
+				record(servicesName, &dnsmessage.PTRResource{PTR: name("_ipp._tcp.local.")}),
+				record("_ipp._tcp.local.", &dnsmessage.PTRResource{PTR: name("Office Printer._ipp._tcp.local.")}),
+				record("Office Printer._ipp._tcp.local.", &dnsmessage.SRVResource{Target: name("printer.local."), Port: 631}),
+				record("Office Printer._ipp._tcp.local.", &dnsmessage.TXTResource{TXT: []string{"ty=LaserJet"}}),
+				record("printer.local.", &dnsmessage.AResource{A: [4]byte{192, 168, 1, 20}}),
+				record("_ssh._tcp.local.", &dnsmessage.PTRResource{PTR: name("nas._ssh._tcp.local.")}),
+			} {
+				b.handle(r)
+			}
+
+			require.Equal(t, []string{
+				"TypePTR _ipp._tcp.local.",
+				"TypeSRV Office Printer._ipp._tcp.local.",
+				"TypeTXT Office Printer._ipp._tcp.local.",
+				"TypeA printer.local.",
+				"TypeSRV nas._ssh._tcp.local.",
+				"TypeTXT nas._ssh._tcp.local.",
+			}, queries)
+
+			// the ssh service never resolved a host so only the printer is returned
+			services := b.services()
+			require.Len(t, services, 1)
+			require.Equal(t, "Office Printer", services[0].Instance)
+			require.Equal(t, "_ipp._tcp", services[0].Type)
+			require.Equal(t, "printer.local.", services[0].Host)
+			require.Equal(t, 631, services[0].Port)
+			require.Equal(t, []string{"ty=LaserJet"}, services[0].Text)
+			require.Len(t, services[0].IPs, 1)
+			require.Equal(t, "192.168.1.20", services[0].IPs[0].String())
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("service type of non DNS-SD name", func(t *testing.T) {
+			require.False(t, isServiceType("printer.local."))
+			require.False(t, isServiceType(servicesName))
+		})
+	})
+}
+
+func name(s string) dnsmessage.Name {
+	return dnsmessage.MustNewName(s)
+}
+
+func record(n string, body dnsmessage.ResourceBody) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name(n), Class: dnsmessage.ClassINET},
+		Body:   body,
+	}
+}
@@ -0,0 +1,243 @@
+package mtr
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/fuskovic/networker/v3/internal/resolve"
+	"github.com/fuskovic/networker/v3/internal/traceroute"
+)
+
+const (
+	notAvailable = "N/A"
+	// noReply is the hostname of hops that haven't replied to any probe.
+	noReply = "*"
+)
+
+// DefaultInterval is the time between the start of consecutive cycles unless configured otherwise.
+const DefaultInterval = time.Second
+
+// Options configures how the path to a host is monitored.
+type Options struct {
+	// Trace configures the probes sent to each hop. Queries is ignored since each hop is probed once per cycle.
+	Trace traceroute.Options
+	// Interval is the time between the start of consecutive cycles. Defaults to DefaultInterval.
+	Interval time.Duration
+	// Cycles is how many times every hop is probed. Hops are probed until the context is done if it's 0.
+	Cycles int
+}
+
+// Hop summarizes the replies of a router, or the host itself, on the path to a host.
+type Hop struct {
+	TTL      int    `json:"ttl" yaml:"ttl" table:"HOP"`
+	IP       net.IP `json:"ip" yaml:"ip" table:"IP"`
+	Hostname string `json:"hostname" yaml:"hostname" table:"HOSTNAME"`
+	// Loss is the percentage of probes that went unanswered.
+	Loss  float64       `json:"loss" yaml:"loss" table:"LOSS%"`
+	Sent  int           `json:"sent" yaml:"sent" table:"SENT"`
+	Last  time.Duration `json:"last_rtt" yaml:"last_rtt" table:"LAST"`
+	Avg   time.Duration `json:"avg_rtt" yaml:"avg_rtt" table:"AVG"`
+	Best  time.Duration `json:"best_rtt" yaml:"best_rtt" table:"BEST"`
+	Worst time.Duration `json:"worst_rtt" yaml:"worst_rtt" table:"WORST"`
+	// Jitter is the mean difference between the round-trip times of consecutive replies.
+	Jitter time.Duration `json:"jitter" yaml:"jitter" table:"JITTER"`
+	ASN    string        `json:"asn,omitempty" yaml:"asn,omitempty" table:"ASN"`
+	ISP    string        `json:"isp,omitempty" yaml:"isp,omitempty" table:"ISP"`
+}
+
+// Run probes every hop on the path to ip once per cycle and calls fn with a summary of the hops after each one.
+// The summary after the last cycle is returned. Run stops early without an error once ctx is done.
+func Run(ctx context.Context, ip net.IP, opts Options, fn func(cycle int, hops []Hop)) ([]Hop, error) {
+	tracer, err := traceroute.New(ip, opts.Trace)
+	if err != nil {
+		return nil, err
+	}
+	defer tracer.Close()
+
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	// The path is shortened to the first hop that turns out to be the host itself.
+	last := opts.Trace.MaxHops
+	if last <= 0 {
+		last = traceroute.DefaultMaxHops
+	}
+
+	var (
+		hopStats  = make([]stats, last)
+		annotator = newAnnotator(opts.Trace)
+		hops      []Hop
+	)
+
+	for cycle := 1; opts.Cycles == 0 || cycle <= opts.Cycles; cycle++ {
+		start := time.Now()
+
+		var (
+			wg      sync.WaitGroup
+			replies = make([]*traceroute.Reply, last)
+			lost    = make([]bool, last)
+		)
+		for i := range replies {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				reply, err := tracer.Probe(ctx, i+1)
+				replies[i] = reply
+				// Probes interrupted by ctx weren't given the chance to be answered.
+				lost[i] = err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+			}(i)
+		}
+		wg.Wait()
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		for i := range replies {
+			if replies[i] != nil || lost[i] {
+				hopStats[i].add(replies[i])
+			}
+			if replies[i] != nil && replies[i].Reached {
+				last = min(last, i+1)
+			}
+		}
+
+		hops = summarize(hopStats[:last], annotator)
+		if fn != nil {
+			fn(cycle, hops)
+		}
+
+		if cycle == opts.Cycles {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(interval - time.Since(start)):
+		}
+	}
+
+	// The final summary includes every hostname and service provider.
+	if hops != nil {
+		annotator.wait()
+		hops = summarize(hopStats[:last], annotator)
+	}
+	return hops, nil
+}
+
+func summarize(hopStats []stats, a *annotator) []Hop {
+	hops := make([]Hop, len(hopStats))
+	for i, s := range hopStats {
+		hops[i] = s.hop(i + 1)
+		if s.ip != nil {
+			hops[i].Hostname, hops[i].ASN, hops[i].ISP = a.annotation(s.ip)
+		}
+	}
+	return hops
+}
+
+// stats are the replies of a single hop.
+type stats struct {
+	// ip is the address of the hop that replied most recently.
+	ip                     net.IP
+	sent, received         int
+	last, sum, best, worst time.Duration
+	jitterSum              time.Duration
+}
+
+// add records the reply to a probe or that it went unanswered if reply is nil.
+func (s *stats) add(reply *traceroute.Reply) {
+	s.sent++
+	if reply == nil {
+		return
+	}
+
+	rtt := reply.RTT
+	if s.received > 0 {
+		s.jitterSum += (rtt - s.last).Abs()
+		s.best = min(s.best, rtt)
+	} else {
+		s.best = rtt
+	}
+	s.received++
+	s.ip = reply.IP
+	s.last = rtt
+	s.sum += rtt
+	s.worst = max(s.worst, rtt)
+}
+
+func (s stats) hop(ttl int) Hop {
+	h := Hop{TTL: ttl, IP: s.ip, Hostname: noReply, Sent: s.sent}
+	if s.sent > 0 {
+		h.Loss = math.Round(float64(s.sent-s.received)/float64(s.sent)*1000) / 10
+	}
+	if s.received == 0 {
+		return h
+	}
+
+	h.Last = s.last.Round(time.Microsecond)
+	h.Avg = (s.sum / time.Duration(s.received)).Round(time.Microsecond)
+	h.Best = s.best.Round(time.Microsecond)
+	h.Worst = s.worst.Round(time.Microsecond)
+	if s.received > 1 {
+		h.Jitter = (s.jitterSum / time.Duration(s.received-1)).Round(time.Microsecond)
+	}
+	return h
+}
+
+// annotator resolves the hostname and service provider of each hop in the background
+// so the summary of a cycle isn't held up by slow lookups.
+type annotator struct {
+	opts        traceroute.Options
+	wg          sync.WaitGroup
+	mu          sync.Mutex
+	annotations map[string]*annotation
+}
+
+type annotation struct {
+	hostname, asn, isp string
+}
+
+func newAnnotator(opts traceroute.Options) *annotator {
+	return &annotator{opts: opts, annotations: make(map[string]*annotation)}
+}
+
+// annotation returns what's been resolved about ip so far and starts resolving it if it's new.
+func (a *annotator) annotation(ip net.IP) (string, string, string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if an, ok := a.annotations[ip.String()]; ok {
+		return an.hostname, an.asn, an.isp
+	}
+	a.annotations[ip.String()] = &annotation{hostname: notAvailable}
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		an := annotation{hostname: notAvailable}
+		if a.opts.Hostnames {
+			an.hostname = resolve.Hostname(ip)
+		}
+		if a.opts.ASN && !resolve.IsPrivate(&ip) {
+			if isp, err := resolve.ServiceProvider(&ip); err == nil {
+				an.asn, an.isp = isp.AutonomousServiceNumber, isp.Name
+			}
+		}
+
+		a.mu.Lock()
+		a.annotations[ip.String()] = &an
+		a.mu.Unlock()
+	}()
+	return notAvailable, "", ""
+}
+
+// wait blocks until every lookup has finished.
+func (a *annotator) wait() {
+	a.wg.Wait()
+}
@@ -0,0 +1,66 @@
+package mtr
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/icmp"
+
+	"github.com/fuskovic/networker/v3/internal/traceroute"
+)
+
+func TestMTR(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("monitor loopback address", func(t *testing.T) {
+			if conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0"); err != nil {
+				t.Skipf("raw icmp sockets are not permitted: %s", err)
+			} else {
+				conn.Close()
+			}
+			var cycles int
+			hops, err := Run(context.Background(), net.IPv4(127, 0, 0, 1), Options{
+				Trace:    traceroute.Options{Protocol: traceroute.ProtocolICMP, Timeout: time.Second},
+				Interval: 10 * time.Millisecond,
+				Cycles:   3,
+			}, func(int, []Hop) { cycles++ })
+			require.NoError(t, err)
+			require.Equal(t, 3, cycles)
+			require.Len(t, hops, 1)
+			require.Equal(t, 3, hops[0].Sent)
+			require.Zero(t, hops[0].Loss)
+		})
+		t.Run("summarize replies of a hop", func(t *testing.T) {
+			var s stats
+			ip := net.ParseIP("10.0.0.1")
+			for _, rtt := range []time.Duration{4 * time.Millisecond, 0, 2 * time.Millisecond, 8 * time.Millisecond} {
+				if rtt == 0 {
+					s.add(nil)
+					continue
+				}
+				s.add(&traceroute.Reply{IP: ip, RTT: rtt})
+			}
+
+			h := s.hop(2)
+			require.Equal(t, 2, h.TTL)
+			require.True(t, h.IP.Equal(ip))
+			require.Equal(t, 4, h.Sent)
+			require.Equal(t, 25.0, h.Loss)
+			require.Equal(t, 8*time.Millisecond, h.Last)
+			require.Equal(t, 2*time.Millisecond, h.Best)
+			require.Equal(t, 8*time.Millisecond, h.Worst)
+			require.Equal(t, 4667*time.Microsecond, h.Avg)
+			require.Equal(t, 4*time.Millisecond, h.Jitter)
+		})
+		t.Run("summarize hop without replies", func(t *testing.T) {
+			var s stats
+			s.add(nil)
+			h := s.hop(1)
+			require.Equal(t, noReply, h.Hostname)
+			require.Equal(t, 100.0, h.Loss)
+		})
+	})
+}
@@ -0,0 +1,22 @@
+package neighbor
+
+import (
+	"net"
+)
+
+// Entry is an entry in the kernel neighbor table which maps an ip address to a hardware address.
+type Entry struct {
+	IP        net.IP
+	MAC       net.HardwareAddr
+	Interface string
+}
+
+// Lookup returns the entry for ip or nil if there isn't one.
+func Lookup(entries []Entry, ip net.IP) *Entry {
+	for i := range entries {
+		if entries[i].IP.Equal(ip) {
+			return &entries[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package neighbor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	procNetARP = "/proc/net/arp"
+
+	// arp flag set once the hardware address of a neighbor has been resolved.
+	atfCom = 0x02
+
+	// neighbor attributes from linux/neighbour.h
+	ndaDst    = 1
+	ndaLLAddr = 2
+
+	// neighbor states from linux/neighbour.h
+	nudIncomplete = 0x01
+	nudFailed     = 0x20
+	// nudNoARP is the state of pseudo-neighbors that are never resolved(e.g. multicast, broadcast and point-to-point).
+	nudNoARP = 0x40
+
+	sizeofNdMsg = 12
+)
+
+// Table returns the entries of the kernel neighbor table.
+// IPv4 neighbors are read from /proc/net/arp and IPv6 neighbors are dumped over netlink.
+func Table() ([]Entry, error) {
+	f, err := os.Open(procNetARP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", procNetARP, err)
+	}
+	defer f.Close()
+
+	entries, err := parseARP(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", procNetARP, err)
+	}
+
+	// IPv6 may be disabled so it's not treated as a failure.
+	if ipv6Entries, err := dumpNeighbors(syscall.AF_INET6); err == nil {
+		entries = append(entries, ipv6Entries...)
+	}
+	return entries, nil
+}
+
+// parseARP parses the contents of /proc/net/arp.
+func parseARP(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	for i := 0; scanner.Scan(); i++ {
+		// skip the column headers
+		if i == 0 {
+			continue
+		}
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(fields[2], 0, 32)
+		if err != nil || flags&atfCom == 0 {
+			continue
+		}
+
+		ip := net.ParseIP(fields[0])
+		mac, err := net.ParseMAC(fields[3])
+		if ip == nil || err != nil || isZero(mac) {
+			continue
+		}
+
+		entries = append(entries,
+			Entry{
+				IP:        ip,
+				MAC:       mac,
+				Interface: fields[5],
+			},
+		)
+	}
+	return entries, scanner.Err()
+}
+
+// dumpNeighbors dumps the neighbor table of family over netlink.
+func dumpNeighbors(family int) ([]Entry, error) {
+	rib, err := syscall.NetlinkRIB(syscall.RTM_GETNEIGH, family)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump neighbors: %w", err)
+	}
+
+	msgs, err := syscall.ParseNetlinkMessage(rib)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse netlink messages: %w", err)
+	}
+
+	var entries []Entry
+	for _, msg := range msgs {
+		if msg.Header.Type != syscall.RTM_NEWNEIGH {
+			continue
+		}
+		if entry := parseNeighborMessage(msg.Data); entry != nil {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries, nil
+}
+
+// parseNeighborMessage parses the ndmsg and attributes of an RTM_NEWNEIGH message.
+func parseNeighborMessage(b []byte) *Entry {
+	if len(b) < sizeofNdMsg {
+		return nil
+	}
+
+	var (
+		ifIndex = int(int32(binary.NativeEndian.Uint32(b[4:8])))
+		state   = binary.NativeEndian.Uint16(b[8:10])
+		entry   Entry
+	)
+
+	if state&(nudIncomplete|nudFailed|nudNoARP) != 0 {
+		return nil
+	}
+
+	for attrs := b[sizeofNdMsg:]; len(attrs) >= syscall.SizeofRtAttr; {
+		length := int(binary.NativeEndian.Uint16(attrs[0:2]))
+		if length < syscall.SizeofRtAttr || length > len(attrs) {
+			break
+		}
+
+		value := attrs[syscall.SizeofRtAttr:length]
+		switch binary.NativeEndian.Uint16(attrs[2:4]) {
+		case ndaDst:
+			entry.IP = net.IP(append([]byte(nil), value...))
+		case ndaLLAddr:
+			entry.MAC = net.HardwareAddr(append([]byte(nil), value...))
+		}
+
+		next := (length + syscall.RTA_ALIGNTO - 1) &^ (syscall.RTA_ALIGNTO - 1)
+		if next > len(attrs) {
+			break
+		}
+		attrs = attrs[next:]
+	}
+
+	if entry.IP == nil || len(entry.MAC) == 0 || isZero(entry.MAC) {
+		return nil
+	}
+
+	if iface, err := net.InterfaceByIndex(ifIndex); err == nil {
+		entry.Interface = iface.Name
+	}
+	return &entry
+}
+
+func isZero(mac net.HardwareAddr) bool {
+	for _, b := range mac {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package neighbor
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNeighbor(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("parse resolved entries from arp table", func(t *testing.T) {
+			table := strings.Join([]string{
+				"IP address       HW type     Flags       HW address            Mask     Device",
+				"192.168.1.1      0x1         0x2         b8:27:eb:12:34:56     *        eth0",
+				"192.168.1.20     0x1         0x0         00:00:00:00:00:00     *        eth0",
+				"10.8.0.5         0x1         0x6         dc:a6:32:ab:cd:ef     *        wlan0",
+			}, "\n")
+			entries, err := parseARP(strings.NewReader(table))
+			require.NoError(t, err)
+			require.Len(t, entries, 2)
+			require.Equal(t, "192.168.1.1", entries[0].IP.String())
+			require.Equal(t, "b8:27:eb:12:34:56", entries[0].MAC.String())
+			require.Equal(t, "eth0", entries[0].Interface)
+			require.Equal(t, "wlan0", entries[1].Interface)
+
+			entry := Lookup(entries, net.ParseIP("10.8.0.5"))
+			require.NotNil(t, entry)
+			require.Equal(t, "dc:a6:32:ab:cd:ef", entry.MAC.String())
+		})
+		t.Run("parse ipv6 neighbor message", func(t *testing.T) {
+			ip := net.ParseIP("fe80::ba27:ebff:fe12:3456")
+			mac, _ := net.ParseMAC("b8:27:eb:12:34:56")
+			entry := parseNeighborMessage(neighborMessage(0x02, ip, mac))
+			require.NotNil(t, entry)
+			require.True(t, entry.IP.Equal(ip))
+			require.Equal(t, mac.String(), entry.MAC.String())
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("lookup ip that is not in the table", func(t *testing.T) {
+			require.Nil(t, Lookup(nil, net.ParseIP("192.168.1.1")))
+		})
+		t.Run("parse failed ipv6 neighbor message", func(t *testing.T) {
+			mac, _ := net.ParseMAC("b8:27:eb:12:34:56")
+			require.Nil(t, parseNeighborMessage(neighborMessage(nudFailed, net.ParseIP("fe80::1"), mac)))
+		})
+		t.Run("parse noarp ipv6 neighbor message", func(t *testing.T) {
+			mac, _ := net.ParseMAC("33:33:00:00:00:01")
+			require.Nil(t, parseNeighborMessage(neighborMessage(nudNoARP, net.ParseIP("ff02::1"), mac)))
+		})
+		t.Run("parse truncated ipv6 neighbor message", func(t *testing.T) {
+			require.Nil(t, parseNeighborMessage([]byte{syscall.AF_INET6}))
+		})
+	})
+}
+
+func neighborMessage(state uint16, ip net.IP, mac net.HardwareAddr) []byte {
+	b := make([]byte, sizeofNdMsg)
+	b[0] = syscall.AF_INET6
+	binary.NativeEndian.PutUint16(b[8:10], state)
+	for _, attr := range []struct {
+		kind  uint16
+		value []byte
+	}{
+		{ndaDst, ip.To16()},
+		{ndaLLAddr, mac},
+	} {
+		length := syscall.SizeofRtAttr + len(attr.value)
+		header := make([]byte, syscall.SizeofRtAttr)
+		binary.NativeEndian.PutUint16(header[0:2], uint16(length))
+		binary.NativeEndian.PutUint16(header[2:4], attr.kind)
+		b = append(b, header...)
+		b = append(b, attr.value...)
+		for len(b)%syscall.RTA_ALIGNTO != 0 {
+			b = append(b, 0)
+		}
+	}
+	return b
+}
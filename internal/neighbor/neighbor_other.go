@@ -0,0 +1,13 @@
+//go:build !linux
+
+package neighbor
+
+import (
+	"errors"
+	"runtime"
+)
+
+// Table returns the entries of the kernel neighbor table.
+func Table() ([]Entry, error) {
+	return nil, errors.New("reading the neighbor table is not supported on " + runtime.GOOS)
+}
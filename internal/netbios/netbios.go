@@ -0,0 +1,196 @@
+package netbios
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	port = 137
+
+	typeNBSTAT = 0x0021
+	classIN    = 0x0001
+
+	// suffixes identify the service a name is registered for
+	suffixWorkstation = 0x00
+
+	// groupFlag is set on names that are shared by multiple hosts(e.g. workgroups and domains).
+	groupFlag = 0x8000
+
+	headerLen    = 12
+	nameEntryLen = 18
+)
+
+// Status is the name table of a host that responded to a node status request.
+type Status struct {
+	// Name is the computer name of the host.
+	Name string
+	// Workgroup is the workgroup or domain the host is a member of.
+	Workgroup string
+	// MAC is the hardware address reported by the host.
+	MAC net.HardwareAddr
+}
+
+// NodeStatus sends a node status request to each of ips and returns the statuses of the hosts that responded.
+// The returned map is keyed by the string representation of each ip that responded.
+// Responses are collected until timeout elapses or ctx is done.
+func NodeStatus(ctx context.Context, timeout time.Duration, ips []net.IP) (map[string]Status, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero})
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for netbios responses: %w", err)
+	}
+	defer conn.Close()
+
+	wanted := make(map[string]bool)
+	for i, ip := range ips {
+		if ip.To4() == nil {
+			continue
+		}
+		wanted[ip.String()] = true
+		if _, err := conn.WriteToUDP(request(uint16(i)), &net.UDPAddr{IP: ip, Port: port}); err != nil {
+			// hosts on networks we have no route to are skipped
+			continue
+		}
+	}
+
+	var (
+		statuses = make(map[string]Status)
+		buf      = make([]byte, 1500)
+		deadline = time.Now().Add(timeout)
+	)
+
+	for time.Now().Before(deadline) && len(statuses) < len(wanted) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		_ = conn.SetReadDeadline(minTime(deadline, time.Now().Add(100*time.Millisecond)))
+		n, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			// deadlines and icmp port unreachable errors from hosts
+			// that don't run the name service are expected.
+			continue
+		}
+
+		if !wanted[addr.IP.String()] {
+			continue
+		}
+
+		status, err := parseResponse(buf[:n])
+		if err != nil {
+			continue
+		}
+		statuses[addr.IP.String()] = *status
+	}
+	return statuses, nil
+}
+
+// request builds a node status request for the wildcard name.
+func request(id uint16) []byte {
+	b := make([]byte, headerLen)
+	binary.BigEndian.PutUint16(b[0:2], id)
+	binary.BigEndian.PutUint16(b[4:6], 1) // one question
+
+	b = append(b, encodeName("*")...)
+	b = binary.BigEndian.AppendUint16(b, typeNBSTAT)
+	b = binary.BigEndian.AppendUint16(b, classIN)
+	return b
+}
+
+// encodeName encodes a netbios name using the first-level encoding described in RFC 1001 section 14.1.
+func encodeName(name string) []byte {
+	padded := make([]byte, 16)
+	copy(padded, name)
+
+	b := []byte{32}
+	for _, c := range padded {
+		b = append(b, 'A'+(c>>4), 'A'+(c&0x0f))
+	}
+	return append(b, 0)
+}
+
+// parseResponse parses the name table and statistics of a node status response.
+func parseResponse(b []byte) (*Status, error) {
+	if len(b) < headerLen {
+		return nil, errors.New("response too short")
+	}
+	if b[2]&0x80 == 0 {
+		return nil, errors.New("not a response")
+	}
+	if binary.BigEndian.Uint16(b[6:8]) == 0 {
+		return nil, errors.New("response has no answers")
+	}
+
+	// skip the answer name which is either a pointer or a sequence of labels
+	i := headerLen
+	for i < len(b) {
+		if b[i]&0xc0 == 0xc0 {
+			i += 2
+			break
+		}
+		if b[i] == 0 {
+			i++
+			break
+		}
+		i += int(b[i]) + 1
+	}
+
+	// type(2), class(2), ttl(4), rdlength(2), number of names(1)
+	if i+11 > len(b) {
+		return nil, errors.New("answer truncated")
+	}
+	if binary.BigEndian.Uint16(b[i:i+2]) != typeNBSTAT {
+		return nil, errors.New("not a node status response")
+	}
+
+	count := int(b[i+10])
+	names := b[i+11:]
+	if count*nameEntryLen > len(names) {
+		return nil, errors.New("name table truncated")
+	}
+
+	var status Status
+	for j := 0; j < count; j++ {
+		entry := names[j*nameEntryLen : (j+1)*nameEntryLen]
+		name := strings.TrimRight(string(entry[:15]), " \x00")
+		suffix := entry[15]
+		isGroup := binary.BigEndian.Uint16(entry[16:18])&groupFlag != 0
+
+		if suffix != suffixWorkstation {
+			continue
+		}
+		if isGroup && status.Workgroup == "" {
+			status.Workgroup = name
+		}
+		if !isGroup && status.Name == "" {
+			status.Name = name
+		}
+	}
+
+	// the statistics that follow the name table start with the hardware address
+	if stats := names[count*nameEntryLen:]; len(stats) >= 6 {
+		mac := net.HardwareAddr(append([]byte(nil), stats[:6]...))
+		if mac.String() != "00:00:00:00:00:00" {
+			status.MAC = mac
+		}
+	}
+
+	if status.Name == "" {
+		return nil, errors.New("response has no computer name")
+	}
+	return &status, nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
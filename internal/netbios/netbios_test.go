@@ -0,0 +1,78 @@
+package netbios
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetBIOS(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("encode wildcard name", func(t *testing.T) {
+			name := encodeName("*")
+			require.Len(t, name, 34)
+			require.Equal(t, "CKAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA", string(name[1:33]))
+		})
+		t.Run("parse node status response", func(t *testing.T) {
+			status, err := parseResponse(response(
+				nameEntry("OFFICE-PC", 0x00, false),
+				nameEntry("OFFICE-PC", 0x20, false),
+				nameEntry("WORKGROUP", 0x00, true),
+			))
+			require.NoError(t, err)
+			require.Equal(t, "OFFICE-PC", status.Name)
+			require.Equal(t, "WORKGROUP", status.Workgroup)
+			require.Equal(t, "00:15:5d:01:02:03", status.MAC.String())
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("parse request as response", func(t *testing.T) {
+			status, err := parseResponse(request(1))
+			require.Nil(t, status)
+			require.Error(t, err)
+		})
+		t.Run("parse response without computer name", func(t *testing.T) {
+			status, err := parseResponse(response(nameEntry("WORKGROUP", 0x00, true)))
+			require.Nil(t, status)
+			require.Error(t, err)
+		})
+		t.Run("parse truncated response", func(t *testing.T) {
+			b := response(nameEntry("OFFICE-PC", 0x00, false))
+			status, err := parseResponse(b[:len(b)-50])
+			require.Nil(t, status)
+			require.Error(t, err)
+		})
+	})
+}
+
+func nameEntry(name string, suffix byte, group bool) []byte {
+	entry := []byte(name + "               ")[:15]
+	entry = append(entry, suffix)
+	var flags uint16
+	if group {
+		flags |= groupFlag
+	}
+	return binary.BigEndian.AppendUint16(entry, flags)
+}
+
+func response(entries ...[]byte) []byte {
+	b := make([]byte, headerLen)
+	binary.BigEndian.PutUint16(b[2:4], 0x8400)
+	binary.BigEndian.PutUint16(b[6:8], 1)
+	b = append(b, encodeName("*")...)
+	b = binary.BigEndian.AppendUint16(b, typeNBSTAT)
+	b = binary.BigEndian.AppendUint16(b, classIN)
+	b = binary.BigEndian.AppendUint32(b, 0)
+
+	rdata := []byte{byte(len(entries))}
+	for _, entry := range entries {
+		rdata = append(rdata, entry...)
+	}
+	rdata = append(rdata, 0x00, 0x15, 0x5d, 0x01, 0x02, 0x03)
+	rdata = append(rdata, make([]byte, 40)...)
+
+	b = binary.BigEndian.AppendUint16(b, uint16(len(rdata)))
+	return append(b, rdata...)
+}
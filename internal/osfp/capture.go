@@ -0,0 +1,141 @@
+package osfp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+)
+
+const (
+	tcpFlagSYN = 0x02
+	tcpFlagACK = 0x10
+)
+
+// Capture records the tcp handshake responses received by the current device.
+// It requires a raw socket so it's only available to privileged users and
+// not every platform(e.g. windows and macos) delivers tcp segments to raw sockets.
+type Capture struct {
+	conn *net.IPConn
+	mu   sync.Mutex
+	seen map[string]SYNACK
+	done chan struct{}
+}
+
+// Listen starts capturing tcp handshake responses until the capture is closed.
+func Listen() (*Capture, error) {
+	conn, err := net.ListenIP("ip4:tcp", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open raw tcp socket: %w", err)
+	}
+
+	c := &Capture{
+		conn: conn,
+		seen: make(map[string]SYNACK),
+		done: make(chan struct{}),
+	}
+	go c.read()
+	return c, nil
+}
+
+func (c *Capture) read() {
+	defer close(c.done)
+	buf := make([]byte, 1500)
+	for {
+		// The ipv4 header is stripped from raw socket reads.
+		n, addr, err := c.conn.ReadFromIP(buf)
+		if err != nil {
+			return
+		}
+
+		port, synack, ok := parseSYNACK(buf[:n])
+		if !ok {
+			continue
+		}
+
+		c.mu.Lock()
+		c.seen[net.JoinHostPort(addr.IP.String(), strconv.Itoa(port))] = *synack
+		c.mu.Unlock()
+	}
+}
+
+// SYNACK returns the first handshake response captured from any of the ports of ip.
+func (c *Capture) SYNACK(ip net.IP, ports ...int) *SYNACK {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, port := range ports {
+		if s, ok := c.seen[net.JoinHostPort(ip.String(), strconv.Itoa(port))]; ok {
+			return &s
+		}
+	}
+	return nil
+}
+
+// Close stops the capture.
+func (c *Capture) Close() error {
+	if c == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	<-c.done
+	return err
+}
+
+// parseSYNACK parses a tcp segment and returns its source port if it's a handshake response.
+func parseSYNACK(b []byte) (int, *SYNACK, bool) {
+	if len(b) < 20 {
+		return 0, nil, false
+	}
+
+	offset := int(b[12]>>4) * 4
+	if offset < 20 || offset > len(b) || b[13]&(tcpFlagSYN|tcpFlagACK) != tcpFlagSYN|tcpFlagACK {
+		return 0, nil, false
+	}
+
+	return int(binary.BigEndian.Uint16(b[0:2])), &SYNACK{
+		Window:  binary.BigEndian.Uint16(b[14:16]),
+		Options: parseOptions(b[20:offset]),
+	}, true
+}
+
+// parseOptions returns the layout of tcp options using the same names as p0f.
+func parseOptions(b []byte) []string {
+	var options []string
+	for i := 0; i < len(b); {
+		kind := b[i]
+		switch kind {
+		case 0:
+			return append(options, "eol")
+		case 1:
+			options = append(options, "nop")
+			i++
+			continue
+		}
+
+		if i+1 >= len(b) || b[i+1] < 2 {
+			return options
+		}
+
+		switch kind {
+		case 2:
+			options = append(options, "mss")
+		case 3:
+			options = append(options, "ws")
+		case 4:
+			options = append(options, "sok")
+		case 5:
+			options = append(options, "sack")
+		case 8:
+			options = append(options, "ts")
+		default:
+			options = append(options, "?"+strconv.Itoa(int(kind)))
+		}
+		i += int(b[i+1])
+	}
+	return options
+}
@@ -0,0 +1,175 @@
+package osfp
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+const (
+	Unknown       OS = "unknown"
+	Linux         OS = "linux"
+	Windows       OS = "windows"
+	MacOS         OS = "macos"
+	NetworkDevice OS = "network-device"
+)
+
+const (
+	ConfidenceNone   Confidence = "none"
+	ConfidenceLow    Confidence = "low"
+	ConfidenceMedium Confidence = "medium"
+	ConfidenceHigh   Confidence = "high"
+)
+
+var (
+	// candidates is the order ties between operating systems are broken in.
+	candidates = []OS{Linux, Windows, MacOS, NetworkDevice}
+
+	windowsPorts = []int{135, 139, 445, 3389}
+	macOSPorts   = []int{548}
+)
+
+// OS is an operating system family.
+type OS string
+
+// Confidence is how strongly the signals of a device agree on its operating system.
+type Confidence string
+
+// Guess is the operating system a device is most likely running.
+type Guess struct {
+	OS         OS         `json:"name" yaml:"name" table:"OS"`
+	Confidence Confidence `json:"confidence" yaml:"confidence" table:"OS_CONFIDENCE"`
+}
+
+// Signals are the characteristics of a device an operating system is guessed from.
+type Signals struct {
+	// TTL is the time-to-live of an icmp echo reply or 0 if the device didn't respond.
+	TTL int
+	// SYNACK is the handshake response from one of the open ports or nil if none were captured.
+	SYNACK    *SYNACK
+	OpenPorts []int
+}
+
+// Match guesses the operating system of a device by scoring each of its signals.
+// Signals that are specific to a single operating system(e.g. smb ports) outweigh ones that are shared by several(e.g. a ttl of 64).
+func Match(s Signals) Guess {
+	scores := make(map[OS]int)
+
+	switch initialTTL(s.TTL) {
+	case 64:
+		scores[Linux]++
+		scores[MacOS]++
+	case 128:
+		scores[Windows] += 2
+	case 255:
+		scores[NetworkDevice] += 2
+	}
+
+	if s.SYNACK != nil {
+		if os, weight := s.SYNACK.match(); os != Unknown {
+			scores[os] += weight
+		}
+	}
+
+	hasAny := func(ports []int) bool {
+		return slices.ContainsFunc(ports, func(p int) bool { return slices.Contains(s.OpenPorts, p) })
+	}
+	switch {
+	case hasAny(windowsPorts):
+		scores[Windows] += 2
+	case hasAny(macOSPorts):
+		scores[MacOS] += 2
+	case slices.Contains(s.OpenPorts, 22):
+		scores[Linux]++
+	}
+
+	var best, runnerUp OS
+	for _, os := range candidates {
+		switch {
+		case best == "" || scores[os] > scores[best]:
+			best, runnerUp = os, best
+		case runnerUp == "" || scores[os] > scores[runnerUp]:
+			runnerUp = os
+		}
+	}
+	return Guess{
+		OS:         best,
+		Confidence: confidence(scores[best], scores[best]-scores[runnerUp]),
+	}.normalize()
+}
+
+// Local returns the operating system of the current device.
+func Local() Guess {
+	switch runtime.GOOS {
+	case "linux", "android":
+		return Guess{Linux, ConfidenceHigh}
+	case "windows":
+		return Guess{Windows, ConfidenceHigh}
+	case "darwin", "ios":
+		return Guess{MacOS, ConfidenceHigh}
+	}
+	return Guess{OS(runtime.GOOS), ConfidenceHigh}
+}
+
+// String formats g for tables(e.g. linux(high)).
+func (g *Guess) String() string {
+	if g == nil {
+		return "N/A"
+	}
+	return fmt.Sprintf("%s(%s)", g.OS, g.Confidence)
+}
+
+func (g Guess) normalize() Guess {
+	if g.Confidence == ConfidenceNone {
+		return Guess{Unknown, ConfidenceNone}
+	}
+	return g
+}
+
+func confidence(score, margin int) Confidence {
+	switch {
+	case score == 0:
+		return ConfidenceNone
+	case score >= 4 && margin >= 2:
+		return ConfidenceHigh
+	case margin >= 2, score >= 3 && margin >= 1:
+		return ConfidenceMedium
+	}
+	return ConfidenceLow
+}
+
+// initialTTL rounds ttl up to the most common initial ttl it could have been sent with.
+// Devices on the local network are rarely more than a few hops away.
+func initialTTL(ttl int) int {
+	for _, initial := range []int{32, 64, 128, 255} {
+		if ttl > 0 && ttl <= initial {
+			return initial
+		}
+	}
+	return 0
+}
+
+// SYNACK is the response to a tcp handshake.
+type SYNACK struct {
+	Window uint16
+	// Options is the layout of the tcp options(e.g. mss,nop,ws,sok,ts).
+	Options []string
+}
+
+// match returns the operating system the tcp stack of s is characteristic of and how much weight it carries.
+func (s SYNACK) match() (OS, int) {
+	layout := strings.Join(s.Options, ",")
+	switch {
+	case strings.HasPrefix(layout, "mss,sok,ts"):
+		return Linux, 2
+	case strings.HasPrefix(layout, "mss,nop,ws") && slices.Contains(s.Options, "ts") && s.Window == 65535:
+		return MacOS, 2
+	case strings.HasPrefix(layout, "mss,nop,ws") && !slices.Contains(s.Options, "ts"):
+		return Windows, 2
+	case layout == "mss":
+		// Embedded tcp stacks tend to only advertise a maximum segment size.
+		return NetworkDevice, 1
+	}
+	return Unknown, 0
+}
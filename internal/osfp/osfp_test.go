@@ -0,0 +1,116 @@
+package osfp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		for _, test := range []struct {
+			name     string
+			signals  Signals
+			expected Guess
+		}{
+			{
+				name:     "match windows using ttl and smb port",
+				signals:  Signals{TTL: 128, OpenPorts: []int{135, 445}},
+				expected: Guess{Windows, ConfidenceHigh},
+			},
+			{
+				name:     "match windows using ttl",
+				signals:  Signals{TTL: 127},
+				expected: Guess{Windows, ConfidenceMedium},
+			},
+			{
+				name: "match linux using ttl, syn-ack and ssh port",
+				signals: Signals{
+					TTL:       64,
+					SYNACK:    &SYNACK{Window: 65160, Options: []string{"mss", "sok", "ts", "nop", "ws"}},
+					OpenPorts: []int{22},
+				},
+				expected: Guess{Linux, ConfidenceHigh},
+			},
+			{
+				name: "match macos using ttl, syn-ack and afp port",
+				signals: Signals{
+					TTL:       64,
+					SYNACK:    &SYNACK{Window: 65535, Options: []string{"mss", "nop", "ws", "nop", "nop", "ts", "sok", "eol"}},
+					OpenPorts: []int{548},
+				},
+				expected: Guess{MacOS, ConfidenceHigh},
+			},
+			{
+				name:     "match network device using ttl",
+				signals:  Signals{TTL: 255},
+				expected: Guess{NetworkDevice, ConfidenceMedium},
+			},
+			{
+				name:     "match ambiguous ttl with low confidence",
+				signals:  Signals{TTL: 64},
+				expected: Guess{Linux, ConfidenceLow},
+			},
+			{
+				name:     "match without signals",
+				signals:  Signals{},
+				expected: Guess{Unknown, ConfidenceNone},
+			},
+		} {
+			t.Run(test.name, func(t *testing.T) {
+				require.Equal(t, test.expected, Match(test.signals))
+			})
+		}
+		t.Run("parse syn-ack", func(t *testing.T) {
+			segment := []byte{
+				0x01, 0xbb, 0xc3, 0x50, // ports
+				0, 0, 0, 1, 0, 0, 0, 2, // sequence and acknowledgement numbers
+				0xa0, 0x12, 0xfe, 0x88, // data offset, flags and window
+				0, 0, 0, 0, // checksum and urgent pointer
+				2, 4, 0x05, 0xb4, 4, 2, 8, 10, 0, 0, 0, 1, 0, 0, 0, 0, 1, 3, 3, 7,
+			}
+			port, synack, ok := parseSYNACK(segment)
+			require.True(t, ok)
+			require.Equal(t, 443, port)
+			require.Equal(t, uint16(65160), synack.Window)
+			require.Equal(t, []string{"mss", "sok", "ts", "nop", "ws"}, synack.Options)
+		})
+		t.Run("capture syn-ack from loopback listener", func(t *testing.T) {
+			c, err := Listen()
+			if err != nil {
+				t.Skipf("raw sockets are not permitted: %s", err)
+			}
+			defer c.Close()
+
+			l, err := net.Listen("tcp4", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer l.Close()
+
+			conn, err := net.Dial("tcp4", l.Addr().String())
+			require.NoError(t, err)
+			conn.Close()
+
+			port := l.Addr().(*net.TCPAddr).Port
+			require.Eventually(t, func() bool {
+				return c.SYNACK(net.IPv4(127, 0, 0, 1), port) != nil
+			}, time.Second, 10*time.Millisecond)
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("parse syn", func(t *testing.T) {
+			segment := make([]byte, 20)
+			segment[12], segment[13] = 0x50, tcpFlagSYN
+			_, synack, ok := parseSYNACK(segment)
+			require.Nil(t, synack)
+			require.False(t, ok)
+		})
+		t.Run("parse truncated segment", func(t *testing.T) {
+			_, synack, ok := parseSYNACK([]byte{0x01, 0xbb})
+			require.Nil(t, synack)
+			require.False(t, ok)
+		})
+	})
+}
@@ -0,0 +1,172 @@
+package oui
+
+import (
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fuskovic/networker/v3/internal/config"
+)
+
+// FileName is the name of the file in the networker config directory that overrides the embedded database.
+const FileName = "oui.csv"
+
+var (
+	// embedded is a gzipped snapshot of the IEEE MA-L registry(see make oui).
+	//go:embed oui.csv.gz
+	embedded []byte
+
+	loadOnce sync.Once
+	loaded   Database
+	loadErr  error
+)
+
+// Record is the vendor of a hardware address.
+type Record struct {
+	MAC    string `json:"mac" yaml:"mac" table:"MAC"`
+	Vendor string `json:"vendor" yaml:"vendor" table:"VENDOR"`
+}
+
+// Database maps IEEE assignments(OUI, MA-M and MA-S prefixes) to organization names.
+type Database map[string]string
+
+// Parse parses an IEEE registry csv(e.g. https://standards-oui.ieee.org/oui/oui.csv).
+func Parse(r io.Reader) (Database, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv: %w", err)
+	}
+
+	db := make(Database)
+	for i, record := range records {
+		if len(record) < 3 {
+			return nil, fmt.Errorf("line %d: expected at least 3 columns", i+1)
+		}
+
+		// skip the column headers
+		if i == 0 && record[1] == "Assignment" {
+			continue
+		}
+
+		assignment := strings.ToUpper(strings.TrimSpace(record[1]))
+		if assignment == "" || strings.Trim(assignment, "0123456789ABCDEF") != "" {
+			return nil, fmt.Errorf("line %d: invalid assignment %q", i+1, record[1])
+		}
+		db[assignment] = strings.TrimSpace(record[2])
+	}
+
+	if len(db) == 0 {
+		return nil, errors.New("no assignments found")
+	}
+	return db, nil
+}
+
+// Vendor returns the name of the organization mac was assigned to or an empty string if it's unknown.
+// MA-S and MA-M assignments take precedence over the OUI they were carved out of.
+func (db Database) Vendor(mac net.HardwareAddr) string {
+	if len(mac) < 3 {
+		return ""
+	}
+
+	prefix := strings.ToUpper(hex.EncodeToString(mac))
+	for _, length := range []int{9, 7, 6} {
+		if len(prefix) < length {
+			continue
+		}
+		if vendor, ok := db[prefix[:length]]; ok {
+			return vendor
+		}
+	}
+	return ""
+}
+
+// Vendor looks up the vendor of mac in the database installed by Update, falling back to the embedded database.
+func Vendor(mac net.HardwareAddr) string {
+	_ = Load()
+	return loaded.Vendor(mac)
+}
+
+// Load loads the database installed by Update, or the embedded database if one isn't installed.
+// The embedded database is used if the installed one can't be read, in which case the error is returned.
+// Vendor loads the database on first use so Load only needs to be called to find out whether it's invalid.
+func Load() error {
+	loadOnce.Do(func() {
+		loaded, loadErr = load()
+	})
+	return loadErr
+}
+
+func load() (Database, error) {
+	embeddedDB, err := parseEmbedded()
+	if err != nil {
+		panic(fmt.Errorf("failed to parse embedded oui database: %w", err))
+	}
+
+	path, err := config.Path(FileName)
+	if err != nil {
+		return embeddedDB, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return embeddedDB, nil
+		}
+		return embeddedDB, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	db, err := Parse(f)
+	if err != nil {
+		return embeddedDB, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// parseEmbedded decompresses and parses the embedded database.
+func parseEmbedded() (Database, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(embedded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress: %w", err)
+	}
+	defer zr.Close()
+	return Parse(zr)
+}
+
+// Update validates the IEEE registry csv at src and installs it in the networker config directory
+// where it takes precedence over the embedded database. It returns the number of assignments installed.
+func Update(src string) (int, error) {
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", src, err)
+	}
+
+	db, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", src, err)
+	}
+
+	dst, err := config.Path(FileName)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", filepath.Dir(dst), err)
+	}
+
+	if err := os.WriteFile(dst, b, 0o644); err != nil {
+		return 0, fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+	return len(db), nil
+}
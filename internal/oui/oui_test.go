@@ -0,0 +1,82 @@
+package oui
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/fuskovic/networker/v3/internal/test"
+)
+
+func TestOUI(t *testing.T) {
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("lookup vendor in embedded database", func(t *testing.T) {
+			db, err := parseEmbedded()
+			require.NoError(t, err)
+			mac, err := net.ParseMAC("b8:27:eb:12:34:56")
+			require.NoError(t, err)
+			require.Equal(t, "Raspberry Pi Foundation", db.Vendor(mac))
+		})
+		t.Run("lookup vendor of assignment carved out of an oui", func(t *testing.T) {
+			db, err := Parse(strings.NewReader(strings.Join([]string{
+				"Registry,Assignment,Organization Name,Organization Address",
+				"MA-L,70B3D5,IEEE Registration Authority,",
+				"MA-S,70B3D5123,Example Sensors Ltd,",
+			}, "\n")))
+			require.NoError(t, err)
+
+			mac, _ := net.ParseMAC("70:b3:d5:12:34:56")
+			require.Equal(t, "Example Sensors Ltd", db.Vendor(mac))
+
+			mac, _ = net.ParseMAC("70:b3:d5:ff:34:56")
+			require.Equal(t, "IEEE Registration Authority", db.Vendor(mac))
+		})
+		t.Run("update installs database in config dir", func(t *testing.T) {
+			test.ConfigDir(t)
+
+			src := filepath.Join(t.TempDir(), "downloaded.csv")
+			require.NoError(t, os.WriteFile(src, []byte("MA-L,001122,Example Networks,\n"), 0o644))
+
+			n, err := Update(src)
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+
+			db, err := load()
+			require.NoError(t, err)
+			mac, _ := net.ParseMAC("00:11:22:33:44:55")
+			require.Equal(t, "Example Networks", db.Vendor(mac))
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("lookup vendor of unknown mac", func(t *testing.T) {
+			db, err := parseEmbedded()
+			require.NoError(t, err)
+			mac, _ := net.ParseMAC("02:00:00:00:00:01")
+			require.Empty(t, db.Vendor(mac))
+		})
+		t.Run("parse csv with invalid assignment", func(t *testing.T) {
+			db, err := Parse(strings.NewReader("MA-L,not-hex,Example Networks,\n"))
+			require.Nil(t, db)
+			require.Error(t, err)
+		})
+		t.Run("load invalid database from config dir", func(t *testing.T) {
+			path := filepath.Join(test.ConfigDir(t), FileName)
+			require.NoError(t, os.WriteFile(path, []byte("==> mam.csv <==\n"), 0o644))
+
+			// The embedded database is still used to look up vendors.
+			db, err := load()
+			require.ErrorContains(t, err, path)
+			mac, _ := net.ParseMAC("b8:27:eb:12:34:56")
+			require.Equal(t, "Raspberry Pi Foundation", db.Vendor(mac))
+		})
+		t.Run("update with file that does not exist", func(t *testing.T) {
+			n, err := Update(filepath.Join(t.TempDir(), "missing.csv"))
+			require.Zero(t, n)
+			require.Error(t, err)
+		})
+	})
+}
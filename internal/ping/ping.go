@@ -0,0 +1,283 @@
+package ping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"slices"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	protocolICMP     = 1
+	protocolICMPIPv6 = 58
+)
+
+// payload is the data carried by echo requests unless a size is requested.
+const payload = "networker"
+
+// Reply is the response to an icmp echo request.
+type Reply struct {
+	IP  net.IP
+	RTT time.Duration
+	// TTL is the time-to-live(or hop limit) of the reply. It's 0 if the platform doesn't expose it.
+	TTL int
+}
+
+// Echo sends an icmp echo request to ip and waits up to timeout for a reply.
+// Unprivileged datagram sockets are used where the platform allows them, otherwise a raw socket is opened.
+func Echo(ctx context.Context, ip net.IP, timeout time.Duration) (*Reply, error) {
+	conn, err := listen(ip)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return echo(ctx, conn, ip, timeout, []byte(payload))
+}
+
+// echo sends an icmp echo request carrying data to ip over conn and waits up to timeout for a reply.
+func echo(ctx context.Context, conn *icmp.PacketConn, ip net.IP, timeout time.Duration, data []byte) (*Reply, error) {
+	var (
+		isIPv4   = ip.To4() != nil
+		dst      net.Addr
+		reqType  icmp.Type = ipv4.ICMPTypeEcho
+		respType icmp.Type = ipv4.ICMPTypeEchoReply
+		proto              = protocolICMP
+		id                 = rand.Intn(0xffff)
+		seq                = rand.Intn(0xffff)
+	)
+
+	if !isIPv4 {
+		reqType, respType, proto = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply, protocolICMPIPv6
+	}
+
+	// Datagram sockets are addressed with a udp address and the kernel assigns the echo identifier.
+	if _, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		dst = &net.UDPAddr{IP: ip}
+	} else {
+		dst = &net.IPAddr{IP: ip}
+	}
+
+	// The ttl is only available through control messages which aren't supported on every platform.
+	if isIPv4 {
+		_ = conn.IPv4PacketConn().SetControlMessage(ipv4.FlagTTL, true)
+	} else {
+		_ = conn.IPv6PacketConn().SetControlMessage(ipv6.FlagHopLimit, true)
+	}
+
+	req, err := (&icmp.Message{
+		Type: reqType,
+		Body: &icmp.Echo{
+			ID:   id,
+			Seq:  seq,
+			Data: data,
+		},
+	}).Marshal(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal echo request: %w", err)
+	}
+
+	start := time.Now()
+	if _, err := conn.WriteTo(req, dst); err != nil {
+		return nil, fmt.Errorf("failed to send echo request to %s: %w", ip, err)
+	}
+
+	var (
+		buf      = make([]byte, 1500+len(data))
+		deadline = start.Add(timeout)
+	)
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		_ = conn.SetReadDeadline(minTime(deadline, time.Now().Add(100*time.Millisecond)))
+
+		var (
+			n    int
+			ttl  int
+			peer net.Addr
+		)
+		if isIPv4 {
+			var cm *ipv4.ControlMessage
+			n, cm, peer, err = conn.IPv4PacketConn().ReadFrom(buf)
+			if cm != nil {
+				ttl = cm.TTL
+			}
+		} else {
+			var cm *ipv6.ControlMessage
+			n, cm, peer, err = conn.IPv6PacketConn().ReadFrom(buf)
+			if cm != nil {
+				ttl = cm.HopLimit
+			}
+		}
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read echo reply: %w", err)
+		}
+		rtt := time.Since(start)
+
+		if !addrIP(peer).Equal(ip) {
+			continue
+		}
+
+		msg, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil || msg.Type != respType {
+			continue
+		}
+
+		echo, ok := msg.Body.(*icmp.Echo)
+		if !ok || echo.Seq != seq {
+			continue
+		}
+
+		// Raw sockets receive every echo reply so the identifier is checked too.
+		if _, ok := dst.(*net.IPAddr); ok && echo.ID != id {
+			continue
+		}
+
+		return &Reply{
+			IP:  ip,
+			RTT: rtt,
+			TTL: ttl,
+		}, nil
+	}
+	return nil, fmt.Errorf("no echo reply from %s within %s", ip, timeout)
+}
+
+// Multicast sends an icmpv6 echo request to the link-local all-nodes group on iface and
+// returns the address of every node that replied within timeout.
+func Multicast(ctx context.Context, iface net.Interface, timeout time.Duration) ([]net.IP, error) {
+	conn, err := listen(net.IPv6unspecified)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var (
+		dst net.Addr = &net.IPAddr{IP: net.IPv6linklocalallnodes, Zone: iface.Name}
+		id           = rand.Intn(0xffff)
+		seq          = rand.Intn(0xffff)
+	)
+	if _, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		dst = &net.UDPAddr{IP: net.IPv6linklocalallnodes, Zone: iface.Name}
+	}
+
+	req, err := (&icmp.Message{
+		Type: ipv6.ICMPTypeEchoRequest,
+		Body: &icmp.Echo{
+			ID:   id,
+			Seq:  seq,
+			Data: []byte(payload),
+		},
+	}).Marshal(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal echo request: %w", err)
+	}
+
+	if _, err := conn.WriteTo(req, dst); err != nil {
+		return nil, fmt.Errorf("failed to send echo request on %s: %w", iface.Name, err)
+	}
+
+	var (
+		ips      []net.IP
+		buf      = make([]byte, 1500)
+		deadline = time.Now().Add(timeout)
+	)
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		_ = conn.SetReadDeadline(minTime(deadline, time.Now().Add(100*time.Millisecond)))
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read echo reply: %w", err)
+		}
+
+		msg, err := icmp.ParseMessage(protocolICMPIPv6, buf[:n])
+		if err != nil || msg.Type != ipv6.ICMPTypeEchoReply {
+			continue
+		}
+
+		echo, ok := msg.Body.(*icmp.Echo)
+		if !ok || echo.Seq != seq {
+			continue
+		}
+
+		// Raw sockets receive every echo reply so the identifier is checked too.
+		if _, ok := dst.(*net.IPAddr); ok && echo.ID != id {
+			continue
+		}
+
+		ip := addrIP(peer)
+		if !slices.ContainsFunc(ips, ip.Equal) {
+			ips = append(ips, ip)
+		}
+	}
+	return ips, nil
+}
+
+// listen opens an unprivileged icmp socket, falling back to a raw socket.
+func listen(ip net.IP) (*icmp.PacketConn, error) {
+	var errs []error
+	for _, method := range []Method{MethodICMPDatagram, MethodICMPRaw} {
+		conn, err := listenMethod(method, ip)
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, fmt.Errorf("failed to open icmp socket: %w", errors.Join(errs...))
+}
+
+// listenMethod opens an icmp socket for the address family of ip using method.
+func listenMethod(method Method, ip net.IP) (*icmp.PacketConn, error) {
+	isIPv4 := ip.To4() != nil
+	switch {
+	case method == MethodICMPDatagram && isIPv4:
+		return icmp.ListenPacket("udp4", "0.0.0.0")
+	case method == MethodICMPDatagram:
+		return icmp.ListenPacket("udp6", "::")
+	case method == MethodICMPRaw && isIPv4:
+		return icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	case method == MethodICMPRaw:
+		return icmp.ListenPacket("ip6:ipv6-icmp", "::")
+	}
+	return nil, fmt.Errorf("unsupported icmp method %q", method)
+}
+
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP
+	case *net.IPAddr:
+		return a.IP
+	}
+	return nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
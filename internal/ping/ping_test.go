@@ -0,0 +1,112 @@
+package ping
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEcho(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("echo loopback address", func(t *testing.T) {
+			if _, err := listen(net.IPv4(127, 0, 0, 1)); err != nil {
+				t.Skipf("icmp sockets are not permitted: %s", err)
+			}
+			reply, err := Echo(context.Background(), net.IPv4(127, 0, 0, 1), time.Second)
+			require.NoError(t, err)
+			require.True(t, reply.IP.Equal(net.IPv4(127, 0, 0, 1)))
+			require.Positive(t, reply.RTT)
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("echo with cancelled context", func(t *testing.T) {
+			if _, err := listen(net.IPv4(127, 0, 0, 1)); err != nil {
+				t.Skipf("icmp sockets are not permitted: %s", err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			reply, err := Echo(ctx, net.IPv4(192, 0, 2, 1), time.Second)
+			require.Nil(t, reply)
+			require.ErrorIs(t, err, context.Canceled)
+		})
+	})
+}
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("summarize replies to a series of echo requests", func(t *testing.T) {
+			s := Summarize(4, []time.Duration{2 * time.Millisecond, 4 * time.Millisecond, 6 * time.Millisecond})
+			require.Equal(t, 3, s.Received)
+			require.Equal(t, 25.0, s.Loss)
+			require.Equal(t, 2*time.Millisecond, s.Min)
+			require.Equal(t, 4*time.Millisecond, s.Avg)
+			require.Equal(t, 6*time.Millisecond, s.Max)
+			require.Equal(t, time.Duration(1632993), s.StdDev)
+			require.Equal(t, "25% 2.00/4.00/6.00/1.63ms", s.String())
+		})
+		t.Run("summarize unanswered echo requests", func(t *testing.T) {
+			s := Summarize(3, nil)
+			require.Equal(t, 100.0, s.Loss)
+			require.Zero(t, s.Avg)
+			require.Equal(t, "100%", s.String())
+		})
+		t.Run("format nil statistics", func(t *testing.T) {
+			var s *Statistics
+			require.Equal(t, "N/A", s.String())
+		})
+	})
+}
+
+func TestPinger(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("detect available method", func(t *testing.T) {
+			require.Contains(t, []Method{MethodICMPDatagram, MethodICMPRaw, MethodTCP}, Detect())
+		})
+		t.Run("ping open and closed tcp ports", func(t *testing.T) {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			open := l.Addr().(*net.TCPAddr).Port
+
+			closed, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			closedPort := closed.Addr().(*net.TCPAddr).Port
+			require.NoError(t, closed.Close())
+			require.NoError(t, l.Close())
+
+			for _, port := range []int{open, closedPort} {
+				reply, err := TCP(context.Background(), net.IPv4(127, 0, 0, 1), time.Second, port)
+				require.NoError(t, err)
+				require.Positive(t, reply.RTT)
+			}
+		})
+		t.Run("summarize tcp pings", func(t *testing.T) {
+			p := NewPinger(MethodTCP, 1)
+			p.Count = 2
+			p.Interval = 10 * time.Millisecond
+			p.Ports = []int{1}
+			stats := p.Ping(context.Background(), net.IPv4(127, 0, 0, 1))
+			require.Equal(t, MethodTCP, stats.Method)
+			require.Equal(t, 2, stats.Received)
+			require.Zero(t, stats.Loss)
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("ping without tcp ports", func(t *testing.T) {
+			reply, err := TCP(context.Background(), net.IPv4(127, 0, 0, 1), time.Second)
+			require.Nil(t, reply)
+			require.Error(t, err)
+		})
+		t.Run("ping with cancelled context", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			stats := NewPinger(MethodTCP, 1).Ping(ctx, net.IPv4(192, 0, 2, 1))
+			require.Zero(t, stats.Sent)
+		})
+	})
+}
@@ -0,0 +1,170 @@
+package ping
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Method is how a Pinger probes hosts.
+type Method string
+
+const (
+	// MethodICMPDatagram sends icmp echo requests over an unprivileged datagram socket.
+	// Linux only permits them for the groups in net.ipv4.ping_group_range.
+	MethodICMPDatagram Method = "icmp-datagram"
+	// MethodICMPRaw sends icmp echo requests over a raw socket which requires elevated privileges.
+	MethodICMPRaw Method = "icmp-raw"
+	// MethodTCP connects to common ports and treats accepted and refused connections alike as replies.
+	MethodTCP Method = "tcp"
+)
+
+// DefaultWorkers is how many hosts a Pinger probes at once unless configured otherwise.
+const DefaultWorkers = 256
+
+// DefaultTCPPorts are the ports connected to when hosts are probed with MethodTCP.
+var DefaultTCPPorts = []int{80, 443, 22, 445}
+
+// Detect returns the first icmp method the process is permitted to use, or MethodTCP if neither is.
+func Detect() Method {
+	for _, method := range []Method{MethodICMPDatagram, MethodICMPRaw} {
+		if conn, err := listenMethod(method, net.IPv4zero); err == nil {
+			conn.Close()
+			return method
+		}
+	}
+	return MethodTCP
+}
+
+// Pinger probes hosts using a bounded pool of workers that is shared by every caller.
+type Pinger struct {
+	// Method is how hosts are probed.
+	Method Method
+	// Count is how many probes are sent to each host. Hosts are probed until the context is done if it's 0.
+	Count int
+	// Interval is the time between the start of consecutive probes sent to the same host.
+	Interval time.Duration
+	// Timeout is how long to wait for the reply to each probe.
+	Timeout time.Duration
+	// Size is the number of data bytes carried by each echo request. A short default payload is sent if it's 0.
+	Size int
+	// TTL is the time-to-live(or hop limit) of each echo request. The system default is used if it's 0.
+	TTL int
+	// Ports are the ports connected to when Method is MethodTCP.
+	Ports []int
+
+	workers chan struct{}
+}
+
+// Result is the outcome of a single probe.
+type Result struct {
+	Seq int           `json:"seq" yaml:"seq"`
+	RTT time.Duration `json:"rtt,omitempty" yaml:"rtt,omitempty"`
+	TTL int           `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	// Error describes why the probe went unanswered.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// NewPinger initializes a Pinger that probes at most workers hosts at once using method.
+// The method is detected if it's empty.
+func NewPinger(method Method, workers int) *Pinger {
+	if method == "" {
+		method = Detect()
+	}
+	return &Pinger{
+		Method:   method,
+		Count:    1,
+		Interval: time.Second,
+		Timeout:  time.Second,
+		Ports:    DefaultTCPPorts,
+		workers:  make(chan struct{}, max(workers, 1)),
+	}
+}
+
+// Ping probes ip and summarizes the replies. It blocks while every worker is busy.
+// Fewer probes are sent if ctx is done.
+func (p *Pinger) Ping(ctx context.Context, ip net.IP) Statistics {
+	return p.Stream(ctx, ip, nil)
+}
+
+// Stream is like Ping but calls fn with the result of each probe as soon as it's known.
+func (p *Pinger) Stream(ctx context.Context, ip net.IP, fn func(Result)) Statistics {
+	select {
+	case p.workers <- struct{}{}:
+		defer func() { <-p.workers }()
+	case <-ctx.Done():
+		return Statistics{Method: p.Method}
+	}
+
+	var (
+		sent int
+		ttl  int
+		rtts []time.Duration
+	)
+	for seq := 1; p.Count == 0 || seq <= p.Count; seq++ {
+		start := time.Now()
+		reply, err := p.Probe(ctx, ip)
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			break
+		}
+		sent++
+
+		result := Result{Seq: seq}
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			rtts = append(rtts, reply.RTT)
+			ttl = reply.TTL
+			result.RTT, result.TTL = reply.RTT, reply.TTL
+		}
+		if fn != nil {
+			fn(result)
+		}
+
+		if seq == p.Count {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(p.Interval - time.Since(start)):
+		}
+	}
+
+	stats := Summarize(sent, rtts)
+	stats.Method = p.Method
+	stats.TTL = ttl
+	return stats
+}
+
+// Probe sends a single probe to ip and waits up to Timeout for a reply.
+func (p *Pinger) Probe(ctx context.Context, ip net.IP) (*Reply, error) {
+	if p.Method == MethodTCP {
+		return TCP(ctx, ip, p.Timeout, p.Ports...)
+	}
+
+	conn, err := listenMethod(p.Method, ip)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if p.TTL > 0 {
+		if ip.To4() != nil {
+			err = conn.IPv4PacketConn().SetTTL(p.TTL)
+		} else {
+			err = conn.IPv6PacketConn().SetHopLimit(p.TTL)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to set ttl: %w", err)
+		}
+	}
+
+	data := []byte(payload)
+	if p.Size > 0 {
+		data = bytes.Repeat(data, p.Size/len(data)+1)[:p.Size]
+	}
+	return echo(ctx, conn, ip, p.Timeout, data)
+}
@@ -0,0 +1,78 @@
+package ping
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"time"
+)
+
+// Statistics summarizes the replies to a series of echo requests.
+type Statistics struct {
+	// Method is how the probes were sent.
+	Method   Method `json:"method" yaml:"method" table:"METHOD"`
+	Sent     int    `json:"sent" yaml:"sent" table:"SENT"`
+	Received int    `json:"received" yaml:"received" table:"RECEIVED"`
+	// Loss is the percentage of probes that went unanswered.
+	Loss   float64       `json:"loss" yaml:"loss" table:"LOSS"`
+	Min    time.Duration `json:"min_rtt" yaml:"min_rtt" table:"MIN"`
+	Avg    time.Duration `json:"avg_rtt" yaml:"avg_rtt" table:"AVG"`
+	Max    time.Duration `json:"max_rtt" yaml:"max_rtt" table:"MAX"`
+	StdDev time.Duration `json:"stddev_rtt" yaml:"stddev_rtt" table:"STDDEV"`
+	// TTL is the time-to-live(or hop limit) of the last reply. It's 0 if it isn't known.
+	TTL int `json:"ttl,omitempty" yaml:"ttl,omitempty" table:"-"`
+}
+
+// Summary is the outcome of pinging a host.
+type Summary struct {
+	Host       string `json:"host" yaml:"host" table:"HOST"`
+	IP         net.IP `json:"ip" yaml:"ip" table:"IP"`
+	Statistics `yaml:",inline" table:"_"`
+	// Results are the results of each probe in the order they were sent.
+	Results []Result `json:"results" yaml:"results" table:"-"`
+}
+
+// String returns the loss and round-trip times of s formatted as "0% 1.20/1.50/2.10/0.30ms".
+func (s *Statistics) String() string {
+	if s == nil || s.Sent == 0 {
+		return "N/A"
+	}
+	if s.Received == 0 {
+		return fmt.Sprintf("%.0f%%", s.Loss)
+	}
+	return fmt.Sprintf("%.0f%% %s/%s/%s/%sms",
+		s.Loss, millis(s.Min), millis(s.Avg), millis(s.Max), millis(s.StdDev),
+	)
+}
+
+// Summarize computes the statistics of sent probes that were answered after rtts.
+func Summarize(sent int, rtts []time.Duration) Statistics {
+	s := Statistics{Sent: sent, Received: len(rtts)}
+	if sent > 0 {
+		s.Loss = float64(sent-len(rtts)) / float64(sent) * 100
+	}
+	if len(rtts) == 0 {
+		return s
+	}
+
+	var sum time.Duration
+	s.Min = rtts[0]
+	for _, rtt := range rtts {
+		sum += rtt
+		s.Min = min(s.Min, rtt)
+		s.Max = max(s.Max, rtt)
+	}
+	s.Avg = sum / time.Duration(len(rtts))
+
+	var variance float64
+	for _, rtt := range rtts {
+		d := float64(rtt - s.Avg)
+		variance += d * d
+	}
+	s.StdDev = time.Duration(math.Sqrt(variance / float64(len(rtts))))
+	return s
+}
+
+func millis(d time.Duration) string {
+	return fmt.Sprintf("%.2f", float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,60 @@
+package ping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// TCP connects to each of ports on ip at once and returns the first reply.
+// A refused connection counts as a reply since only a host that is up can refuse it.
+func TCP(ctx context.Context, ip net.IP, timeout time.Duration, ports ...int) (*Reply, error) {
+	if len(ports) == 0 {
+		return nil, errors.New("no tcp ports to probe")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		start   = time.Now()
+		replies = make(chan *Reply, len(ports))
+		errs    = make(chan error, len(ports))
+	)
+
+	for _, port := range ports {
+		go func(port int) {
+			var d net.Dialer
+			conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(ip.String(), strconv.Itoa(port)))
+			if err == nil {
+				conn.Close()
+			}
+			if err == nil || IsRefused(err) {
+				replies <- &Reply{IP: ip, RTT: time.Since(start)}
+				return
+			}
+			errs <- err
+		}(port)
+	}
+
+	for range ports {
+		select {
+		case reply := <-replies:
+			return reply, nil
+		case <-errs:
+		}
+	}
+
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil, ctx.Err()
+	}
+	return nil, fmt.Errorf("no tcp reply from %s within %s", ip, timeout)
+}
+
+// IsRefused reports whether err is the result of a host rejecting a connection.
+func IsRefused(err error) bool {
+	return errors.Is(err, errConnRefused)
+}
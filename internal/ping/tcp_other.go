@@ -0,0 +1,7 @@
+//go:build !windows
+
+package ping
+
+import "syscall"
+
+var errConnRefused error = syscall.ECONNREFUSED
@@ -0,0 +1,5 @@
+package ping
+
+import "golang.org/x/sys/windows"
+
+var errConnRefused error = windows.WSAECONNREFUSED
@@ -0,0 +1,31 @@
+//go:build darwin || freebsd
+
+package pmtu
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+var errMsgSize error = syscall.EMSGSIZE
+
+// dontFragment sets the don't fragment flag of packets sent over c.
+func dontFragment(c syscall.Conn, isIPv4 bool) error {
+	rc, err := c.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sockErr error
+	if err := rc.Control(func(fd uintptr) {
+		if isIPv4 {
+			sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_IP, unix.IP_DONTFRAG, 1)
+		} else {
+			sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_IPV6, unix.IPV6_DONTFRAG, 1)
+		}
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
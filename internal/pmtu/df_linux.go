@@ -0,0 +1,30 @@
+package pmtu
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+var errMsgSize error = syscall.EMSGSIZE
+
+// dontFragment sets the don't fragment flag of packets sent over c. The path mtu the kernel
+// has already learned is ignored so packets up to the mtu of the interface can be sent.
+func dontFragment(c syscall.Conn, isIPv4 bool) error {
+	rc, err := c.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sockErr error
+	if err := rc.Control(func(fd uintptr) {
+		if isIPv4 {
+			sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_IP, unix.IP_MTU_DISCOVER, unix.IP_PMTUDISC_PROBE)
+		} else {
+			sockErr = unix.SetsockoptInt(int(fd), unix.IPPROTO_IPV6, unix.IPV6_MTU_DISCOVER, unix.IPV6_PMTUDISC_PROBE)
+		}
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
@@ -0,0 +1,16 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package pmtu
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"syscall"
+)
+
+var errMsgSize = errors.New("message too long")
+
+func dontFragment(syscall.Conn, bool) error {
+	return fmt.Errorf("setting the don't fragment flag isn't supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,35 @@
+package pmtu
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// ipDontFragment and ipv6DontFrag aren't defined by the windows package.
+	ipDontFragment = 14
+	ipv6DontFrag   = 14
+)
+
+var errMsgSize error = windows.WSAEMSGSIZE
+
+// dontFragment sets the don't fragment flag of packets sent over c.
+func dontFragment(c syscall.Conn, isIPv4 bool) error {
+	rc, err := c.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sockErr error
+	if err := rc.Control(func(fd uintptr) {
+		if isIPv4 {
+			sockErr = windows.SetsockoptInt(windows.Handle(fd), windows.IPPROTO_IP, ipDontFragment, 1)
+		} else {
+			sockErr = windows.SetsockoptInt(windows.Handle(fd), windows.IPPROTO_IPV6, ipv6DontFrag, 1)
+		}
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
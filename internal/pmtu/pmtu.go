@@ -0,0 +1,454 @@
+package pmtu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+const (
+	protocolICMP     = 1
+	protocolUDP      = 17
+	protocolICMPIPv6 = 58
+)
+
+const (
+	// ProtocolICMP probes the path with icmp echo requests.
+	ProtocolICMP Protocol = "icmp"
+	// ProtocolUDP probes the path with udp datagrams sent to a port that is unlikely to be used.
+	ProtocolUDP Protocol = "udp"
+)
+
+const (
+	DefaultTimeout = 2 * time.Second
+	DefaultRetries = 2
+	DefaultUDPPort = 33434
+)
+
+const (
+	// minIPv4MTU is the smallest mtu every ipv4 link must support.
+	minIPv4MTU = 68
+	// minIPv6MTU is the smallest mtu every ipv6 link must support.
+	minIPv6MTU = 1280
+	// maxPacketLen is the largest ip packet since the length field of the ip header is 16 bits.
+	maxPacketLen = 65535
+	// probeHeaderLen is the length of both icmp echo and udp headers.
+	probeHeaderLen = 8
+)
+
+// Protocol is the kind of packet sent to probe the path.
+type Protocol string
+
+// Options configures how the path mtu is discovered.
+type Options struct {
+	// Protocol is the kind of packet sent to probe the path. Defaults to ProtocolICMP.
+	Protocol Protocol
+	// Max is the largest packet size probed. Defaults to, and is capped at, the mtu of the interface packets to the host leave from.
+	Max int
+	// Timeout is how long to wait for the reply to each probe. Defaults to DefaultTimeout.
+	Timeout time.Duration
+	// Retries is how many more times a probe is sent before it's considered too big. Defaults to DefaultRetries.
+	Retries int
+	// Port is the destination port of udp probes. Defaults to DefaultUDPPort.
+	Port int
+}
+
+// Result is the mtu of the path to a host.
+type Result struct {
+	Host         string `json:"host" yaml:"host" table:"HOST"`
+	IP           net.IP `json:"ip" yaml:"ip" table:"IP"`
+	Interface    string `json:"interface" yaml:"interface" table:"INTERFACE"`
+	InterfaceMTU int    `json:"interface_mtu" yaml:"interface_mtu" table:"INTERFACE_MTU"`
+	// MTU is the size of the largest packet that reached the host without being fragmented.
+	MTU int `json:"mtu" yaml:"mtu" table:"PATH_MTU"`
+	// Bottleneck is the router that reported the path mtu. It's nil if the mtu of the interface is
+	// the path mtu or the router that dropped larger packets didn't report it.
+	Bottleneck net.IP `json:"bottleneck,omitempty" yaml:"bottleneck,omitempty" table:"BOTTLENECK"`
+	// Reports are the fragmentation-needed(or packet-too-big) messages received while probing.
+	Reports []Report `json:"reports,omitempty" yaml:"reports,omitempty" table:"-"`
+	Probes  int      `json:"probes" yaml:"probes" table:"PROBES"`
+}
+
+// Report is a message from a router that dropped a probe because it was bigger than the mtu of the next hop.
+type Report struct {
+	From net.IP `json:"from" yaml:"from"`
+	// MTU is the mtu of the next hop. It's 0 if the router didn't include it.
+	MTU int `json:"mtu" yaml:"mtu"`
+	// Size is the size of the probe that was dropped.
+	Size int `json:"size" yaml:"size"`
+}
+
+// Discover finds the size of the largest packet that reaches ip without being fragmented by searching
+// between the minimum mtu of the address family and the mtu of the outgoing interface.
+// Reading the icmp replies requires a raw socket so the user must be privileged.
+func Discover(ctx context.Context, ip net.IP, opts Options) (*Result, error) {
+	opts = withDefaults(opts)
+
+	iface, err := outgoingInterface(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := newProber(ip, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer p.close()
+
+	res := &Result{
+		Host:         ip.String(),
+		IP:           ip,
+		Interface:    iface.Name,
+		InterfaceMTU: iface.MTU,
+	}
+
+	// Interfaces like loopback have mtus larger than any packet that can be sent.
+	lo, hi := minIPv4MTU, min(iface.MTU, maxPacketLen)
+	if !p.isIPv4 {
+		lo = minIPv6MTU
+	}
+	if opts.Max > 0 {
+		hi = min(hi, opts.Max)
+	}
+	if hi < lo {
+		return nil, fmt.Errorf("largest packet size %d is smaller than the minimum mtu %d", hi, lo)
+	}
+
+	res.MTU, err = search(lo, hi, func(size int) (bool, int, error) {
+		return p.fits(ctx, size, res)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range res.Reports {
+		if r.MTU == res.MTU {
+			res.Bottleneck = r.From
+		}
+	}
+	return res, nil
+}
+
+// search returns the largest size between lo and hi that fits. A size that doesn't fit may come
+// with a hint of the largest size that does which is tried next.
+func search(lo, hi int, fits func(size int) (bool, int, error)) (int, error) {
+	// Most paths support the mtu of the interface so it's probed first.
+	ok, hint, err := fits(hi)
+	if err != nil || ok {
+		return hi, err
+	}
+
+	// Failures only mean the probe was too big if the host replies to the smallest one.
+	if ok, _, err := fits(lo); err != nil {
+		return 0, err
+	} else if !ok {
+		return 0, errors.New("no reply to a probe of the minimum size")
+	}
+
+	hi--
+	for lo < hi {
+		size := (lo + hi + 1) / 2
+		// Routers report the mtu of their next hop. Anything bigger would be dropped by them again
+		// so the hint is the answer if it fits.
+		isHint := hint > lo && hint <= hi
+		if isHint {
+			size = hint
+		}
+
+		if ok, hint, err = fits(size); err != nil {
+			return 0, err
+		}
+		switch {
+		case ok && isHint:
+			return size, nil
+		case ok:
+			lo = size
+		default:
+			hi = size - 1
+		}
+	}
+	return lo, nil
+}
+
+func withDefaults(opts Options) Options {
+	if opts.Protocol == "" {
+		opts.Protocol = ProtocolICMP
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.Port == 0 {
+		opts.Port = DefaultUDPPort
+	}
+	return opts
+}
+
+// outgoingInterface returns the interface that packets to ip leave from.
+func outgoingInterface(ip net.IP) (*net.Interface, error) {
+	// Connecting a udp socket picks a route without sending anything.
+	conn, err := net.Dial("udp", net.JoinHostPort(ip.String(), "9"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find route to %s: %w", ip, err)
+	}
+	defer conn.Close()
+	local := conn.LocalAddr().(*net.UDPAddr).IP
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interfaces: %w", err)
+	}
+	for i := range ifaces {
+		addrs, err := ifaces[i].Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(local) {
+				return &ifaces[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("failed to find the interface of %s", local)
+}
+
+// prober sends probes of a particular size with the don't fragment flag set.
+type prober struct {
+	ip     net.IP
+	isIPv4 bool
+	opts   Options
+	// conn receives the icmp replies to every kind of probe and sends icmp probes.
+	conn *net.IPConn
+	// udp sends udp probes.
+	udp *net.UDPConn
+	id  int
+	seq int
+}
+
+func newProber(ip net.IP, opts Options) (*prober, error) {
+	p := &prober{
+		ip:     ip,
+		isIPv4: ip.To4() != nil,
+		opts:   opts,
+		id:     rand.Intn(0xffff),
+	}
+
+	network, address := "ip4:icmp", "0.0.0.0"
+	if !p.isIPv4 {
+		network, address = "ip6:ipv6-icmp", "::"
+	}
+	conn, err := net.ListenPacket(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open raw icmp socket(path mtu discovery requires elevated privileges): %w", err)
+	}
+	p.conn = conn.(*net.IPConn)
+
+	if opts.Protocol == ProtocolUDP {
+		network = "udp4"
+		if !p.isIPv4 {
+			network = "udp6"
+		}
+		if p.udp, err = net.ListenUDP(network, nil); err != nil {
+			p.close()
+			return nil, fmt.Errorf("failed to open udp socket: %w", err)
+		}
+	}
+
+	if err := dontFragment(p.conn, p.isIPv4); err != nil {
+		p.close()
+		return nil, fmt.Errorf("failed to set don't fragment flag: %w", err)
+	}
+	if p.udp != nil {
+		if err := dontFragment(p.udp, p.isIPv4); err != nil {
+			p.close()
+			return nil, fmt.Errorf("failed to set don't fragment flag: %w", err)
+		}
+	}
+	return p, nil
+}
+
+func (p *prober) close() {
+	if p.udp != nil {
+		p.udp.Close()
+	}
+	p.conn.Close()
+}
+
+// fits reports whether a probe of size reached the host. If a router reported
+// the probe was too big, the mtu of its next hop is returned too.
+func (p *prober) fits(ctx context.Context, size int, res *Result) (bool, int, error) {
+	for attempt := 0; attempt <= p.opts.Retries; attempt++ {
+		res.Probes++
+		fits, report, err := p.probe(ctx, size)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			continue
+		}
+		if err != nil {
+			return false, 0, err
+		}
+		if report != nil {
+			res.Reports = append(res.Reports, *report)
+			return false, report.MTU, nil
+		}
+		return fits, 0, nil
+	}
+	return false, 0, nil
+}
+
+// probe sends a single probe of size and waits for a reply.
+// The probe fits if the host replied and is too big if it can't be sent or a router reports it.
+func (p *prober) probe(ctx context.Context, size int) (bool, *Report, error) {
+	p.seq = (p.seq + 1) & 0xffff
+
+	headerLen := ipv4.HeaderLen
+	if !p.isIPv4 {
+		headerLen = ipv6.HeaderLen
+	}
+	data := make([]byte, size-headerLen-probeHeaderLen)
+
+	var err error
+	if p.opts.Protocol == ProtocolUDP {
+		_, err = p.udp.WriteTo(data, &net.UDPAddr{IP: p.ip, Port: p.udpPort()})
+	} else {
+		var typ icmp.Type = ipv4.ICMPTypeEcho
+		if !p.isIPv4 {
+			typ = ipv6.ICMPTypeEchoRequest
+		}
+		var req []byte
+		req, err = (&icmp.Message{Type: typ, Body: &icmp.Echo{ID: p.id, Seq: p.seq, Data: data}}).Marshal(nil)
+		if err != nil {
+			return false, nil, fmt.Errorf("failed to marshal echo request: %w", err)
+		}
+		_, err = p.conn.WriteTo(req, &net.IPAddr{IP: p.ip})
+	}
+	if errors.Is(err, errMsgSize) {
+		// The probe is bigger than the mtu of the interface or a route the kernel already knows.
+		return false, nil, nil
+	}
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to send probe: %w", err)
+	}
+
+	var (
+		buf      = make([]byte, max(size, 1500))
+		deadline = time.Now().Add(p.opts.Timeout)
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return false, nil, err
+		}
+		_ = p.conn.SetReadDeadline(minTime(deadline, time.Now().Add(100*time.Millisecond)))
+		n, peer, err := p.conn.ReadFrom(buf)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			if time.Now().Before(deadline) {
+				continue
+			}
+			return false, nil, err
+		}
+		if err != nil {
+			return false, nil, fmt.Errorf("failed to read reply: %w", err)
+		}
+
+		from := peer.(*net.IPAddr).IP
+		if fits, report, ok := p.match(buf[:n], from, size); ok {
+			return fits, report, nil
+		}
+	}
+}
+
+func (p *prober) udpPort() int {
+	return p.opts.Port + p.seq%1024
+}
+
+// match parses b and reports whether it's a reply to the current probe.
+func (p *prober) match(b []byte, from net.IP, size int) (bool, *Report, bool) {
+	proto := protocolICMP
+	if !p.isIPv4 {
+		proto = protocolICMPIPv6
+	}
+	msg, err := icmp.ParseMessage(proto, b)
+	if err != nil {
+		return false, nil, false
+	}
+
+	var (
+		quoted []byte
+		mtu    int
+	)
+	switch body := msg.Body.(type) {
+	case *icmp.Echo:
+		isReply := msg.Type == ipv4.ICMPTypeEchoReply || msg.Type == ipv6.ICMPTypeEchoReply
+		ok := isReply && p.opts.Protocol == ProtocolICMP && from.Equal(p.ip) && body.ID == p.id && body.Seq == p.seq
+		return ok, nil, ok
+	case *icmp.PacketTooBig:
+		quoted, mtu = body.Data, body.MTU
+	case *icmp.DstUnreach:
+		quoted = body.Data
+		if msg.Type != ipv4.ICMPTypeDestinationUnreachable || msg.Code != 4 {
+			// The host rejecting the udp probe means it arrived in one piece.
+			ok := from.Equal(p.ip) && p.quotes(quoted)
+			return ok, nil, ok
+		}
+		// The next-hop mtu of fragmentation-needed messages is in the otherwise unused half of the header.
+		if len(b) >= 8 {
+			mtu = int(b[6])<<8 | int(b[7])
+		}
+	default:
+		return false, nil, false
+	}
+
+	if !p.quotes(quoted) {
+		return false, nil, false
+	}
+	return false, &Report{From: from, MTU: mtu, Size: size}, true
+}
+
+// quotes reports whether b, the packet quoted by an icmp error, is the current probe.
+func (p *prober) quotes(b []byte) bool {
+	var (
+		protocol int
+		dst      net.IP
+		payload  []byte
+	)
+	switch {
+	case len(b) >= ipv4.HeaderLen && b[0]>>4 == 4:
+		headerLen := int(b[0]&0x0f) * 4
+		if len(b) < headerLen+8 {
+			return false
+		}
+		protocol, dst, payload = int(b[9]), net.IP(b[16:20]), b[headerLen:]
+	case len(b) >= ipv6.HeaderLen+8 && b[0]>>4 == 6:
+		protocol, dst, payload = int(b[6]), net.IP(b[24:40]), b[ipv6.HeaderLen:]
+	default:
+		return false
+	}
+
+	if !dst.Equal(p.ip) {
+		return false
+	}
+	switch protocol {
+	case protocolUDP:
+		return p.opts.Protocol == ProtocolUDP && int(payload[2])<<8|int(payload[3]) == p.udpPort()
+	case protocolICMP, protocolICMPIPv6:
+		id, seq := int(payload[4])<<8|int(payload[5]), int(payload[6])<<8|int(payload[7])
+		return p.opts.Protocol == ProtocolICMP && id == p.id && seq == p.seq
+	}
+	return false
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
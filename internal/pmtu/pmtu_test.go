@@ -0,0 +1,61 @@
+package pmtu
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPMTU(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("discover path mtu of loopback address", func(t *testing.T) {
+			if conn, err := net.ListenPacket("ip4:icmp", "0.0.0.0"); err != nil {
+				t.Skipf("raw icmp sockets are not permitted: %s", err)
+			} else {
+				conn.Close()
+			}
+			res, err := Discover(context.Background(), net.IPv4(127, 0, 0, 1), Options{Max: 1500})
+			require.NoError(t, err)
+			require.Equal(t, 1500, res.MTU)
+			require.Nil(t, res.Bottleneck)
+		})
+		t.Run("search for the path mtu", func(t *testing.T) {
+			for _, hint := range []int{0, 1380} {
+				var probes []int
+				mtu, err := search(68, 1500, func(size int) (bool, int, error) {
+					probes = append(probes, size)
+					if size <= 1380 {
+						return true, 0, nil
+					}
+					return false, hint, nil
+				})
+				require.NoError(t, err)
+				require.Equal(t, 1380, mtu)
+				if hint > 0 {
+					// Reported mtus are tried right after the probe of the minimum size.
+					require.Equal(t, []int{1500, 68, 1380}, probes)
+				}
+			}
+		})
+		t.Run("search for the path mtu of a path that supports the largest size", func(t *testing.T) {
+			mtu, err := search(1280, 1500, func(int) (bool, int, error) { return true, 0, nil })
+			require.NoError(t, err)
+			require.Equal(t, 1500, mtu)
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("search for the path mtu of a host that doesn't reply", func(t *testing.T) {
+			_, err := search(68, 1500, func(int) (bool, int, error) { return false, 0, nil })
+			require.Error(t, err)
+		})
+		t.Run("search for the path mtu while probes fail", func(t *testing.T) {
+			errProbe := errors.New("probe failed")
+			_, err := search(68, 1500, func(int) (bool, int, error) { return false, 0, errProbe })
+			require.ErrorIs(t, err, errProbe)
+		})
+	})
+}
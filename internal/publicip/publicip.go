@@ -0,0 +1,73 @@
+package publicip
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultEndpoint is the http endpoint used to resolve the public ip if none is configured.
+	DefaultEndpoint = "http://myexternalip.com/raw"
+	// DefaultSTUNServer is a publicly available STUN server.
+	DefaultSTUNServer = "stun.l.google.com:19302"
+
+	timeout = 5 * time.Second
+)
+
+// Resolver resolves the public ip address of the current device.
+type Resolver interface {
+	PublicIP(context.Context) (net.IP, error)
+}
+
+type httpResolver struct {
+	endpoint string
+}
+
+// HTTP initializes a Resolver that reads the public ip from the plain text response body of endpoint.
+func HTTP(endpoint string) Resolver {
+	return &httpResolver{endpoint}
+}
+
+func (r *httpResolver) PublicIP(ctx context.Context) (net.IP, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status from %s: %s", r.endpoint, resp.Status)
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return parseIP(strings.TrimSpace(string(b)))
+}
+
+func parseIP(s string) (net.IP, error) {
+	var ip net.IP
+	if strings.Contains(s, ":") {
+		ip = net.ParseIP(s).To16()
+	} else {
+		ip = net.ParseIP(s).To4()
+	}
+	if ip == nil {
+		return nil, fmt.Errorf("failed to parse %q as an ip address", s)
+	}
+	return ip, nil
+}
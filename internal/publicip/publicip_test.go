@@ -0,0 +1,100 @@
+package publicip
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicIP(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("resolve public ip using http endpoint", func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, "203.0.113.7")
+			}))
+			defer srv.Close()
+
+			ip, err := HTTP(srv.URL).PublicIP(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, "203.0.113.7", ip.String())
+		})
+		t.Run("parse xor mapped ipv4 address from stun binding response", func(t *testing.T) {
+			txID := [12]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+			resp := stunResponse(txID, stunXorMappedAddress, net.ParseIP("203.0.113.7").To4(), true)
+			ip, err := parseStunBindingResponse(resp, txID)
+			require.NoError(t, err)
+			require.Equal(t, "203.0.113.7", ip.String())
+		})
+		t.Run("parse xor mapped ipv6 address from stun binding response", func(t *testing.T) {
+			txID := [12]byte{12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+			resp := stunResponse(txID, stunXorMappedAddress, net.ParseIP("2001:db8::7"), true)
+			ip, err := parseStunBindingResponse(resp, txID)
+			require.NoError(t, err)
+			require.Equal(t, "2001:db8::7", ip.String())
+		})
+		t.Run("parse mapped address from stun binding response", func(t *testing.T) {
+			var txID [12]byte
+			resp := stunResponse(txID, stunMappedAddress, net.ParseIP("198.51.100.1").To4(), false)
+			ip, err := parseStunBindingResponse(resp, txID)
+			require.NoError(t, err)
+			require.Equal(t, "198.51.100.1", ip.String())
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("resolve public ip using http endpoint with invalid body", func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, "<html></html>")
+			}))
+			defer srv.Close()
+
+			ip, err := HTTP(srv.URL).PublicIP(context.Background())
+			require.Nil(t, ip)
+			require.Error(t, err)
+		})
+		t.Run("parse stun binding response with unexpected transaction id", func(t *testing.T) {
+			resp := stunResponse([12]byte{1}, stunXorMappedAddress, net.ParseIP("203.0.113.7").To4(), true)
+			ip, err := parseStunBindingResponse(resp, [12]byte{2})
+			require.Nil(t, ip)
+			require.ErrorIs(t, err, errUnexpectedTransaction)
+		})
+		t.Run("parse truncated stun binding response", func(t *testing.T) {
+			ip, err := parseStunBindingResponse([]byte{0x01, 0x01}, [12]byte{})
+			require.Nil(t, ip)
+			require.Error(t, err)
+		})
+	})
+}
+
+func stunResponse(txID [12]byte, attrType uint16, ip net.IP, xor bool) []byte {
+	family := byte(stunAddressFamilyIPv4)
+	if len(ip) == net.IPv6len {
+		family = stunAddressFamilyIPv6
+	}
+
+	header := stunBindingRequestMessage(txID)
+	binary.BigEndian.PutUint16(header[0:2], stunBindingResponse)
+
+	addr := make([]byte, len(ip))
+	for i := range ip {
+		addr[i] = ip[i]
+		if xor {
+			addr[i] ^= header[4+i]
+		}
+	}
+
+	value := append([]byte{0, family, 0, 0}, addr...)
+	attr := make([]byte, 4)
+	binary.BigEndian.PutUint16(attr[0:2], attrType)
+	binary.BigEndian.PutUint16(attr[2:4], uint16(len(value)))
+	attr = append(attr, value...)
+
+	binary.BigEndian.PutUint16(header[2:4], uint16(len(attr)))
+	return append(header, attr...)
+}
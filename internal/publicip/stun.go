@@ -0,0 +1,166 @@
+package publicip
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// STUN message constants from RFC 5389.
+const (
+	stunHeaderLen         = 20
+	stunMagicCookie       = 0x2112A442
+	stunBindingRequest    = 0x0001
+	stunBindingResponse   = 0x0101
+	stunMappedAddress     = 0x0001
+	stunXorMappedAddress  = 0x0020
+	stunAddressFamilyIPv4 = 0x01
+	stunAddressFamilyIPv6 = 0x02
+)
+
+type stunResolver struct {
+	server string
+}
+
+// STUN initializes a Resolver that discovers the public ip by sending a binding request to a STUN server.
+func STUN(server string) Resolver {
+	return &stunResolver{server}
+}
+
+func (r *stunResolver) PublicIP(ctx context.Context) (net.IP, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", r.server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial stun server %q: %w", r.server, err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var txID [12]byte
+	if _, err := rand.Read(txID[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate transaction id: %w", err)
+	}
+
+	if _, err := conn.Write(stunBindingRequestMessage(txID)); err != nil {
+		return nil, fmt.Errorf("failed to send binding request: %w", err)
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read binding response: %w", err)
+		}
+		ip, err := parseStunBindingResponse(buf[:n], txID)
+		if errors.Is(err, errUnexpectedTransaction) {
+			continue
+		}
+		return ip, err
+	}
+}
+
+var errUnexpectedTransaction = errors.New("unexpected stun transaction id")
+
+func stunBindingRequestMessage(txID [12]byte) []byte {
+	b := make([]byte, stunHeaderLen)
+	binary.BigEndian.PutUint16(b[0:2], stunBindingRequest)
+	binary.BigEndian.PutUint16(b[2:4], 0)
+	binary.BigEndian.PutUint32(b[4:8], stunMagicCookie)
+	copy(b[8:20], txID[:])
+	return b
+}
+
+func parseStunBindingResponse(b []byte, txID [12]byte) (net.IP, error) {
+	if len(b) < stunHeaderLen {
+		return nil, errors.New("stun message too short")
+	}
+	if binary.BigEndian.Uint32(b[4:8]) != stunMagicCookie {
+		return nil, errors.New("invalid stun magic cookie")
+	}
+	if !bytes.Equal(b[8:20], txID[:]) {
+		return nil, errUnexpectedTransaction
+	}
+	if msgType := binary.BigEndian.Uint16(b[0:2]); msgType != stunBindingResponse {
+		return nil, fmt.Errorf("unexpected stun message type %#04x", msgType)
+	}
+
+	length := int(binary.BigEndian.Uint16(b[2:4]))
+	if stunHeaderLen+length > len(b) {
+		return nil, errors.New("stun message truncated")
+	}
+
+	var mapped net.IP
+	attrs := b[stunHeaderLen : stunHeaderLen+length]
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs[0:2])
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:4]))
+		if 4+attrLen > len(attrs) {
+			return nil, errors.New("stun attribute truncated")
+		}
+		value := attrs[4 : 4+attrLen]
+
+		switch attrType {
+		case stunXorMappedAddress:
+			return parseStunAddress(value, b[4:20])
+		case stunMappedAddress:
+			mapped, _ = parseStunAddress(value, nil)
+		}
+
+		// attributes are padded to a multiple of 4 bytes
+		next := 4 + (attrLen+3)&^3
+		if next > len(attrs) {
+			break
+		}
+		attrs = attrs[next:]
+	}
+
+	if mapped == nil {
+		return nil, errors.New("stun response did not include a mapped address")
+	}
+	return mapped, nil
+}
+
+// parseStunAddress parses a (XOR-)MAPPED-ADDRESS attribute value.
+// If key is non-nil the address is xor'd with it as described in RFC 5389 section 15.2.
+func parseStunAddress(value, key []byte) (net.IP, error) {
+	if len(value) < 4 {
+		return nil, errors.New("stun address attribute too short")
+	}
+
+	var ip net.IP
+	switch value[1] {
+	case stunAddressFamilyIPv4:
+		if len(value) < 8 {
+			return nil, errors.New("stun ipv4 address truncated")
+		}
+		ip = make(net.IP, net.IPv4len)
+		copy(ip, value[4:8])
+	case stunAddressFamilyIPv6:
+		if len(value) < 20 {
+			return nil, errors.New("stun ipv6 address truncated")
+		}
+		ip = make(net.IP, net.IPv6len)
+		copy(ip, value[4:20])
+	default:
+		return nil, fmt.Errorf("unknown stun address family %#02x", value[1])
+	}
+
+	for i := range ip {
+		if key != nil {
+			ip[i] ^= key[i]
+		}
+	}
+	return ip, nil
+}
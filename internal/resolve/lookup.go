@@ -1,6 +1,7 @@
 package resolve
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"strings"
@@ -184,6 +185,31 @@ func HostAndAddr(host string) (string, *net.IP, error) {
 	return hostname, &ip, nil
 }
 
+// AddrByHostAndNetwork returns the ip address of host whether host is an ip address or a hostname.
+// The network restricts the address to "ip4" or "ip6". Either is allowed for "ip" but ipv4 is preferred.
+func AddrByHostAndNetwork(host, network string) (net.IP, error) {
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		var err error
+		ips, err = net.DefaultResolver.LookupIP(context.Background(), network, host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up ip addresses for hostname %q: %w", host, err)
+		}
+	}
+
+	for _, ip := range ips {
+		if ip4 := ip.To4(); ip4 != nil && network != "ip6" {
+			return ip4, nil
+		}
+	}
+	for _, ip := range ips {
+		if ip.To4() == nil && network != "ip4" {
+			return ip, nil
+		}
+	}
+	return nil, fmt.Errorf("no %s addresses found for %q", network, host)
+}
+
 // ServiceProvider returns the internet service provider information for ip.
 func ServiceProvider(ip *net.IP) (*InternetServiceProvider, error) {
 	client, err := ipisp.NewDNSClient()
@@ -208,6 +234,29 @@ func ServiceProvider(ip *net.IP) (*InternetServiceProvider, error) {
 	}, nil
 }
 
+// ReverseName returns the name used to lookup the hostname of ip(e.g. 4.3.2.1.in-addr.arpa.).
+func ReverseName(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa.", ip4[3], ip4[2], ip4[1], ip4[0])
+	}
+
+	ip6 := ip.To16()
+	if ip6 == nil {
+		return ""
+	}
+
+	const hexDigits = "0123456789abcdef"
+	var b strings.Builder
+	for i := len(ip6) - 1; i >= 0; i-- {
+		b.WriteByte(hexDigits[ip6[i]&0x0f])
+		b.WriteByte('.')
+		b.WriteByte(hexDigits[ip6[i]>>4])
+		b.WriteByte('.')
+	}
+	b.WriteString("ip6.arpa.")
+	return b.String()
+}
+
 func stripHostname(hostname string) string {
 	hostname = strings.ReplaceAll(hostname, "https://", "")
 	hostname = strings.ReplaceAll(hostname, "http://", "")
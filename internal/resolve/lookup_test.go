@@ -72,6 +72,15 @@ func TestLookup(t *testing.T) {
 			require.NoError(t, err)
 			require.Equal(t, expected, isp)
 		})
+		t.Run("addr by ip address and network", func(t *testing.T) {
+			t.Parallel()
+			ip, err := AddrByHostAndNetwork("192.0.2.1", "ip")
+			require.NoError(t, err)
+			require.Equal(t, "192.0.2.1", ip.String())
+			ip, err = AddrByHostAndNetwork("2001:db8::1", "ip6")
+			require.NoError(t, err)
+			require.Equal(t, "2001:db8::1", ip.String())
+		})
 	})
 	t.Run("ShouldFail", func(t *testing.T) {
 		t.Parallel()
@@ -97,5 +106,11 @@ func TestLookup(t *testing.T) {
 			require.Nil(t, addrs)
 			require.Error(t, err)
 		})
+		t.Run("addr by ip address of another network", func(t *testing.T) {
+			t.Parallel()
+			ip, err := AddrByHostAndNetwork("192.0.2.1", "ip6")
+			require.Nil(t, ip)
+			require.Error(t, err)
+		})
 	})
 }
@@ -0,0 +1,28 @@
+package resolve
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseName(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("reverse name of ipv4 address", func(t *testing.T) {
+			require.Equal(t, "20.1.168.192.in-addr.arpa.", ReverseName(net.ParseIP("192.168.1.20")))
+		})
+		t.Run("reverse name of ipv6 address", func(t *testing.T) {
+			require.Equal(t,
+				"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.e.f.ip6.arpa.",
+				ReverseName(net.ParseIP("fe80::1")),
+			)
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("reverse name of invalid ip", func(t *testing.T) {
+			require.Empty(t, ReverseName(nil))
+		})
+	})
+}
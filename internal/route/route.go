@@ -0,0 +1,28 @@
+package route
+
+import (
+	"net"
+)
+
+// Route is an entry in a kernel routing table.
+type Route struct {
+	// Destination is the network the route matches in cidr notation or default if it matches every address.
+	Destination string `json:"destination" yaml:"destination" table:"DESTINATION"`
+	Gateway     net.IP `json:"gateway,omitempty" yaml:"gateway,omitempty" table:"GATEWAY"`
+	Interface   string `json:"interface,omitempty" yaml:"interface,omitempty" table:"INTERFACE"`
+	// Source is the address packets sent over the route are sent from unless the sender picked one.
+	Source   net.IP `json:"source,omitempty" yaml:"source,omitempty" table:"SOURCE"`
+	Metric   int    `json:"metric" yaml:"metric" table:"METRIC"`
+	Table    string `json:"table" yaml:"table" table:"TABLE"`
+	Protocol string `json:"protocol" yaml:"protocol" table:"PROTOCOL"`
+	// Type is what happens to packets that match the route, like unicast for forwarding them or blackhole for dropping them.
+	Type string `json:"type" yaml:"type" table:"TYPE"`
+}
+
+// Options configures which routes are listed.
+type Options struct {
+	// Network is ip4 or ip6 to only list the routes of that family. Routes of both families are listed otherwise.
+	Network string
+	// Local includes the routes of the local table which the kernel maintains for the addresses of each interface.
+	Local bool
+}
@@ -0,0 +1,333 @@
+package route
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// route protocols from linux/rtnetlink.h
+var protocols = map[uint8]string{
+	unix.RTPROT_UNSPEC:   "unspec",
+	unix.RTPROT_REDIRECT: "redirect",
+	unix.RTPROT_KERNEL:   "kernel",
+	unix.RTPROT_BOOT:     "boot",
+	unix.RTPROT_STATIC:   "static",
+	unix.RTPROT_RA:       "ra",
+	unix.RTPROT_DHCP:     "dhcp",
+	unix.RTPROT_ZEBRA:    "zebra",
+	unix.RTPROT_BIRD:     "bird",
+	unix.RTPROT_BABEL:    "babel",
+	unix.RTPROT_BGP:      "bgp",
+	unix.RTPROT_ISIS:     "isis",
+	unix.RTPROT_OSPF:     "ospf",
+	unix.RTPROT_RIP:      "rip",
+}
+
+// route types from linux/rtnetlink.h
+var types = map[uint8]string{
+	unix.RTN_UNICAST:     "unicast",
+	unix.RTN_LOCAL:       "local",
+	unix.RTN_BROADCAST:   "broadcast",
+	unix.RTN_ANYCAST:     "anycast",
+	unix.RTN_MULTICAST:   "multicast",
+	unix.RTN_BLACKHOLE:   "blackhole",
+	unix.RTN_UNREACHABLE: "unreachable",
+	unix.RTN_PROHIBIT:    "prohibit",
+	unix.RTN_THROW:       "throw",
+	unix.RTN_NAT:         "nat",
+}
+
+// List returns the routes of every routing table except the local table unless it's included by opts.
+// Routes are dumped over netlink.
+func List(opts Options) ([]Route, error) {
+	var families []int
+	switch opts.Network {
+	case "ip4":
+		families = []int{syscall.AF_INET}
+	case "ip6":
+		families = []int{syscall.AF_INET6}
+	default:
+		families = []int{syscall.AF_INET, syscall.AF_INET6}
+	}
+
+	var routes []Route
+	for _, family := range families {
+		rib, err := syscall.NetlinkRIB(syscall.RTM_GETROUTE, family)
+		if err != nil {
+			// IPv6 may be disabled so it's only a failure if it was asked for.
+			if family == syscall.AF_INET6 && opts.Network == "" {
+				continue
+			}
+			return nil, fmt.Errorf("failed to dump routes: %w", err)
+		}
+
+		msgs, err := syscall.ParseNetlinkMessage(rib)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse netlink messages: %w", err)
+		}
+
+		for i := range msgs {
+			if msgs[i].Header.Type != syscall.RTM_NEWROUTE {
+				continue
+			}
+			// Routes the kernel cached for a destination duplicate the routes they were cloned from.
+			if len(msgs[i].Data) >= syscall.SizeofRtMsg && binary.NativeEndian.Uint32(msgs[i].Data[8:12])&unix.RTM_F_CLONED != 0 {
+				continue
+			}
+			for _, r := range parseRouteMessage(&msgs[i]) {
+				if r.Table == "local" && !opts.Local {
+					continue
+				}
+				routes = append(routes, r)
+			}
+		}
+	}
+	return routes, nil
+}
+
+// Get returns the route the kernel would send packets to ip over.
+func Get(ip net.IP) (*Route, error) {
+	family, addr := syscall.AF_INET, ip.To4()
+	if addr == nil {
+		family, addr = syscall.AF_INET6, ip.To16()
+	}
+	if addr == nil {
+		return nil, fmt.Errorf("invalid ip address %s", ip)
+	}
+
+	// Report the table the route was found in rather than the one the result is cached in.
+	r, err := getRoute(family, addr, unix.RTM_F_LOOKUP_TABLE)
+	if err != nil {
+		return nil, err
+	}
+
+	// The route the kernel resolves is specific to ip so the entry of the routing table it matched is looked up too.
+	// Kernels older than 4.13 don't support looking it up so the resolved route is returned on its own.
+	if match, err := getRoute(family, addr, unix.RTM_F_FIB_MATCH); err == nil {
+		r.Destination = match.Destination
+		r.Metric = match.Metric
+		r.Table = match.Table
+		r.Protocol = match.Protocol
+	}
+	return r, nil
+}
+
+// getRoute sends an RTM_GETROUTE request for the route to addr over netlink.
+func getRoute(family int, addr []byte, flags uint32) (*Route, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_ROUTE)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open netlink socket: %w", err)
+	}
+	defer syscall.Close(fd)
+
+	sa := &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}
+	if err := syscall.Bind(fd, sa); err != nil {
+		return nil, fmt.Errorf("failed to bind netlink socket: %w", err)
+	}
+
+	const seq = 1
+	if err := syscall.Sendto(fd, getRouteRequest(seq, family, addr, flags), 0, sa); err != nil {
+		return nil, fmt.Errorf("failed to send route request: %w", err)
+	}
+
+	buf := make([]byte, syscall.Getpagesize())
+	for {
+		n, _, err := syscall.Recvfrom(fd, buf, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to receive route: %w", err)
+		}
+
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse netlink messages: %w", err)
+		}
+
+		for i := range msgs {
+			if msgs[i].Header.Seq != seq {
+				continue
+			}
+			switch msgs[i].Header.Type {
+			case syscall.NLMSG_ERROR:
+				if len(msgs[i].Data) < 4 {
+					return nil, errors.New("truncated netlink error")
+				}
+				if errno := -int32(binary.NativeEndian.Uint32(msgs[i].Data[:4])); errno != 0 {
+					return nil, syscall.Errno(errno)
+				}
+			case syscall.RTM_NEWROUTE:
+				if routes := parseRouteMessage(&msgs[i]); len(routes) > 0 {
+					return &routes[0], nil
+				}
+				return nil, errors.New("invalid route")
+			}
+		}
+	}
+}
+
+// getRouteRequest returns an RTM_GETROUTE request for the route to addr.
+func getRouteRequest(seq uint32, family int, addr []byte, flags uint32) []byte {
+	var (
+		attrLen = syscall.SizeofRtAttr + len(addr)
+		msgLen  = syscall.NLMSG_HDRLEN + syscall.SizeofRtMsg + attrLen
+		b       = make([]byte, msgLen)
+	)
+
+	binary.NativeEndian.PutUint32(b[0:4], uint32(msgLen))
+	binary.NativeEndian.PutUint16(b[4:6], syscall.RTM_GETROUTE)
+	binary.NativeEndian.PutUint16(b[6:8], syscall.NLM_F_REQUEST)
+	binary.NativeEndian.PutUint32(b[8:12], seq)
+
+	rtm := b[syscall.NLMSG_HDRLEN:]
+	rtm[0] = byte(family)
+	rtm[1] = byte(len(addr) * 8)
+	binary.NativeEndian.PutUint32(rtm[8:12], flags)
+
+	attr := rtm[syscall.SizeofRtMsg:]
+	binary.NativeEndian.PutUint16(attr[0:2], uint16(attrLen))
+	binary.NativeEndian.PutUint16(attr[2:4], syscall.RTA_DST)
+	copy(attr[syscall.SizeofRtAttr:], addr)
+	return b
+}
+
+// parseRouteMessage parses the rtmsg and attributes of an RTM_NEWROUTE message.
+// A route with multiple next hops is returned as a route for each of them.
+func parseRouteMessage(msg *syscall.NetlinkMessage) []Route {
+	if len(msg.Data) < syscall.SizeofRtMsg {
+		return nil
+	}
+
+	var (
+		rtm      = msg.Data[:syscall.SizeofRtMsg]
+		family   = rtm[0]
+		dstLen   = int(rtm[1])
+		table    = uint32(rtm[4])
+		r        = Route{Protocol: name(protocols, rtm[5]), Type: name(types, rtm[7])}
+		dst      net.IP
+		oif      int
+		nextHops []byte
+	)
+
+	attrs, err := syscall.ParseNetlinkRouteAttr(msg)
+	if err != nil {
+		return nil
+	}
+
+	for _, attr := range attrs {
+		switch attr.Attr.Type {
+		case syscall.RTA_DST:
+			dst = net.IP(attr.Value)
+		case syscall.RTA_GATEWAY:
+			r.Gateway = net.IP(attr.Value)
+		case syscall.RTA_PREFSRC:
+			r.Source = net.IP(attr.Value)
+		case syscall.RTA_OIF:
+			if len(attr.Value) == 4 {
+				oif = int(int32(binary.NativeEndian.Uint32(attr.Value)))
+			}
+		case syscall.RTA_PRIORITY:
+			if len(attr.Value) == 4 {
+				r.Metric = int(binary.NativeEndian.Uint32(attr.Value))
+			}
+		case unix.RTA_TABLE:
+			// Tables with ids larger than a byte are only reported here.
+			if len(attr.Value) == 4 {
+				table = binary.NativeEndian.Uint32(attr.Value)
+			}
+		case unix.RTA_MULTIPATH:
+			nextHops = attr.Value
+		}
+	}
+
+	switch {
+	case dst != nil:
+		r.Destination = (&net.IPNet{IP: dst, Mask: net.CIDRMask(dstLen, len(dst)*8)}).String()
+	case dstLen == 0:
+		r.Destination = "default"
+	default:
+		// A prefix without an address is a network of zeros.
+		size := net.IPv4len
+		if family == syscall.AF_INET6 {
+			size = net.IPv6len
+		}
+		r.Destination = (&net.IPNet{IP: make(net.IP, size), Mask: net.CIDRMask(dstLen, size*8)}).String()
+	}
+
+	switch table {
+	case unix.RT_TABLE_DEFAULT:
+		r.Table = "default"
+	case unix.RT_TABLE_MAIN:
+		r.Table = "main"
+	case unix.RT_TABLE_LOCAL:
+		r.Table = "local"
+	default:
+		r.Table = strconv.FormatUint(uint64(table), 10)
+	}
+
+	if nextHops == nil {
+		r.Interface = interfaceName(oif)
+		return []Route{r}
+	}
+
+	var routes []Route
+	for len(nextHops) >= unix.SizeofRtNexthop {
+		length := int(binary.NativeEndian.Uint16(nextHops[0:2]))
+		if length < unix.SizeofRtNexthop || length > len(nextHops) {
+			break
+		}
+
+		hop := r
+		hop.Interface = interfaceName(int(int32(binary.NativeEndian.Uint32(nextHops[4:8]))))
+		hop.Gateway = nextHopGateway(nextHops[unix.SizeofRtNexthop:length])
+		routes = append(routes, hop)
+
+		next := (length + unix.RTNH_ALIGNTO - 1) &^ (unix.RTNH_ALIGNTO - 1)
+		if next > len(nextHops) {
+			break
+		}
+		nextHops = nextHops[next:]
+	}
+	return routes
+}
+
+// nextHopGateway returns the gateway in the attributes of a next hop.
+func nextHopGateway(attrs []byte) net.IP {
+	for len(attrs) >= syscall.SizeofRtAttr {
+		length := int(binary.NativeEndian.Uint16(attrs[0:2]))
+		if length < syscall.SizeofRtAttr || length > len(attrs) {
+			break
+		}
+		if binary.NativeEndian.Uint16(attrs[2:4]) == syscall.RTA_GATEWAY {
+			return net.IP(append([]byte(nil), attrs[syscall.SizeofRtAttr:length]...))
+		}
+
+		next := (length + syscall.RTA_ALIGNTO - 1) &^ (syscall.RTA_ALIGNTO - 1)
+		if next > len(attrs) {
+			break
+		}
+		attrs = attrs[next:]
+	}
+	return nil
+}
+
+func interfaceName(index int) string {
+	if index <= 0 {
+		return ""
+	}
+	if iface, err := net.InterfaceByIndex(index); err == nil {
+		return iface.Name
+	}
+	return strconv.Itoa(index)
+}
+
+func name(names map[uint8]string, v uint8) string {
+	if n, ok := names[v]; ok {
+		return n
+	}
+	return strconv.Itoa(int(v))
+}
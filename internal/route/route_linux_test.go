@@ -0,0 +1,122 @@
+package route
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestRoute(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("list loopback routes of the local table", func(t *testing.T) {
+			routes, err := List(Options{Network: "ip4", Local: true})
+			require.NoError(t, err)
+
+			var found bool
+			for _, r := range routes {
+				if r.Destination == "127.0.0.0/8" && r.Type == "local" {
+					found = true
+					require.Equal(t, "lo", r.Interface)
+					require.Equal(t, "local", r.Table)
+				}
+			}
+			require.True(t, found)
+		})
+		t.Run("list routes without the local table", func(t *testing.T) {
+			routes, err := List(Options{})
+			require.NoError(t, err)
+			for _, r := range routes {
+				require.NotEqual(t, "local", r.Table)
+			}
+		})
+		t.Run("get route to loopback address", func(t *testing.T) {
+			r, err := Get(net.IPv4(127, 0, 0, 1))
+			require.NoError(t, err)
+			require.Equal(t, "lo", r.Interface)
+			require.Equal(t, "local", r.Type)
+			require.Equal(t, "127.0.0.1", r.Source.String())
+		})
+		t.Run("parse default route message", func(t *testing.T) {
+			routes := parseRouteMessage(routeMessage(0, unix.RT_TABLE_MAIN,
+				attribute(syscall.RTA_GATEWAY, net.ParseIP("192.168.1.1").To4()),
+				attribute(syscall.RTA_PRIORITY, uint32Bytes(100)),
+			))
+			require.Len(t, routes, 1)
+			require.Equal(t, "default", routes[0].Destination)
+			require.Equal(t, "192.168.1.1", routes[0].Gateway.String())
+			require.Equal(t, 100, routes[0].Metric)
+			require.Equal(t, "main", routes[0].Table)
+			require.Equal(t, "dhcp", routes[0].Protocol)
+			require.Equal(t, "unicast", routes[0].Type)
+		})
+		t.Run("parse route message of a table with a large id", func(t *testing.T) {
+			routes := parseRouteMessage(routeMessage(24, unix.RT_TABLE_COMPAT,
+				attribute(syscall.RTA_DST, net.ParseIP("10.8.0.0").To4()),
+				attribute(unix.RTA_TABLE, uint32Bytes(51820)),
+			))
+			require.Len(t, routes, 1)
+			require.Equal(t, "10.8.0.0/24", routes[0].Destination)
+			require.Equal(t, "51820", routes[0].Table)
+		})
+		t.Run("parse multipath route message", func(t *testing.T) {
+			var nextHops []byte
+			for _, gw := range []string{"10.0.0.1", "10.0.1.1"} {
+				gwAttr := attribute(syscall.RTA_GATEWAY, net.ParseIP(gw).To4())
+				hop := make([]byte, unix.SizeofRtNexthop)
+				binary.NativeEndian.PutUint16(hop[0:2], uint16(unix.SizeofRtNexthop+len(gwAttr)))
+				nextHops = append(append(nextHops, hop...), gwAttr...)
+			}
+			routes := parseRouteMessage(routeMessage(0, unix.RT_TABLE_MAIN, attribute(unix.RTA_MULTIPATH, nextHops)))
+			require.Len(t, routes, 2)
+			require.Equal(t, "10.0.0.1", routes[0].Gateway.String())
+			require.Equal(t, "10.0.1.1", routes[1].Gateway.String())
+			require.Equal(t, "default", routes[1].Destination)
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("get route to invalid ip address", func(t *testing.T) {
+			_, err := Get(net.IP{1, 2})
+			require.Error(t, err)
+		})
+		t.Run("parse truncated route message", func(t *testing.T) {
+			require.Nil(t, parseRouteMessage(&syscall.NetlinkMessage{
+				Header: syscall.NlMsghdr{Type: syscall.RTM_NEWROUTE},
+				Data:   make([]byte, syscall.SizeofRtMsg-1),
+			}))
+		})
+	})
+}
+
+// routeMessage returns an ipv4 RTM_NEWROUTE message of a route learned over dhcp.
+func routeMessage(dstLen int, table uint8, attrs ...[]byte) *syscall.NetlinkMessage {
+	b := make([]byte, syscall.SizeofRtMsg)
+	b[0] = syscall.AF_INET
+	b[1] = byte(dstLen)
+	b[4] = table
+	b[5] = unix.RTPROT_DHCP
+	b[7] = unix.RTN_UNICAST
+	for _, attr := range attrs {
+		b = append(b, attr...)
+	}
+	return &syscall.NetlinkMessage{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWROUTE}, Data: b}
+}
+
+func attribute(typ uint16, value []byte) []byte {
+	length := syscall.SizeofRtAttr + len(value)
+	b := make([]byte, (length+syscall.RTA_ALIGNTO-1)&^(syscall.RTA_ALIGNTO-1))
+	binary.NativeEndian.PutUint16(b[0:2], uint16(length))
+	binary.NativeEndian.PutUint16(b[2:4], typ)
+	copy(b[syscall.SizeofRtAttr:], value)
+	return b
+}
+
+func uint32Bytes(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.NativeEndian.PutUint32(b, v)
+	return b
+}
@@ -0,0 +1,51 @@
+//go:build !linux
+
+package route
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"runtime"
+)
+
+// List returns the routes of every routing table except the local table unless it's included by opts.
+func List(Options) ([]Route, error) {
+	return nil, errors.New("listing routes is not supported on " + runtime.GOOS)
+}
+
+// Get returns the route the kernel would send packets to ip over. Only the interface and source address
+// of the route are known since they're learned from the address a udp socket connected to ip is bound to.
+func Get(ip net.IP) (*Route, error) {
+	conn, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: ip, Port: 9})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find route to %s: %w", ip, err)
+	}
+	defer conn.Close()
+
+	source := conn.LocalAddr().(*net.UDPAddr).IP
+	r := &Route{Source: source, Type: "unicast"}
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interfaces: %w", err)
+	}
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || !ipNet.IP.Equal(source) {
+				continue
+			}
+			r.Interface = iface.Name
+			if ipNet.Contains(ip) {
+				// The destination is on the network of the interface so it's reached without a gateway.
+				r.Destination = (&net.IPNet{IP: ip.Mask(ipNet.Mask), Mask: ipNet.Mask}).String()
+			}
+		}
+	}
+	return r, nil
+}
@@ -4,13 +4,13 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
-	goping "github.com/tatsushid/go-fastping"
-
+	"github.com/fuskovic/networker/v3/internal/osfp"
+	"github.com/fuskovic/networker/v3/internal/ping"
 	"github.com/fuskovic/networker/v3/internal/resolve"
 )
 
@@ -20,10 +20,13 @@ var (
 )
 
 type Scan struct {
-	IP    string `json:"ip" table:"IP"`
-	Host  string `json:"hostname" table:"HOSTNAME"`
-	Ports []int  `json:"open_ports" table:"OPEN_PORTS"`
-	Up    bool   `json:"up" yaml:"up" table:"UP"`
+	IP    string      `json:"ip" table:"IP"`
+	Label string      `json:"label,omitempty" table:"LABEL"`
+	Host  string      `json:"hostname" table:"HOSTNAME"`
+	Ports []int       `json:"open_ports" table:"OPEN_PORTS"`
+	Up    bool        `json:"up" yaml:"up" table:"UP"`
+	OS    *osfp.Guess `json:"os,omitempty" yaml:"os,omitempty" table:"OS"`
+	Tags  []string    `json:"tags,omitempty" table:"TAGS"`
 }
 
 type Scanner interface {
@@ -32,46 +35,39 @@ type Scanner interface {
 
 type scanner struct {
 	sync.Mutex
-	scans         []Scan
+	scans []Scan
+	// ttls are the time-to-live values of the echo replies from each host.
+	ttls          map[string]int
 	shouldScanAll bool
 }
 
 // NewScanner initializes a new port-scanner based on whether or not the user wants to scan all ports or just the well-known ports.
 func New(hosts []string, shouldScanAll bool) Scanner {
 	var (
-		scans []Scan
-		wg    sync.WaitGroup
-		mu    sync.Mutex
+		scans  []Scan
+		ttls   = make(map[string]int)
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		pinger = ping.NewPinger("", ping.DefaultWorkers)
 	)
 
 	for _, host := range hosts {
 		wg.Add(1)
 		go func(ip string) {
 			defer wg.Done()
-			p := goping.NewPinger()
-			_, _ = p.Network("udp")
-
-			netProto := "ip4:icmp"
-			if strings.Index(ip, ":") != -1 {
-				netProto = "ip6:ipv6-icmp"
-			}
-
-			addr, err := net.ResolveIPAddr(netProto, ip)
-			if err != nil {
+			addr := net.ParseIP(ip)
+			if addr == nil {
 				return
 			}
 
-			p.AddIPAddr(addr)
-			p.MaxRTT = time.Second
-
-			s := Scan{IP: ip}
-			p.OnRecv = func(addr *net.IPAddr, t time.Duration) { s.Up = true }
-			if err := p.Run(); err != nil {
-				return
-			}
+			stats := pinger.Ping(context.Background(), addr)
+			s := Scan{IP: ip, Up: stats.Received > 0}
 
 			mu.Lock()
 			scans = append(scans, s)
+			if stats.TTL > 0 {
+				ttls[ip] = stats.TTL
+			}
 			mu.Unlock()
 		}(host)
 	}
@@ -80,11 +76,16 @@ func New(hosts []string, shouldScanAll bool) Scanner {
 	return &scanner{
 		Mutex:         sync.Mutex{},
 		scans:         scans,
+		ttls:          ttls,
 		shouldScanAll: shouldScanAll,
 	}
 }
 
 func (s *scanner) Scan(ctx context.Context) ([]Scan, error) {
+	// Handshake responses can only be captured by privileged users so they're best-effort.
+	capture, _ := osfp.Listen()
+	defer capture.Close()
+
 	var wg sync.WaitGroup
 	for _, scan := range s.scans {
 		if scan.Up {
@@ -103,6 +104,14 @@ func (s *scanner) Scan(ctx context.Context) ([]Scan, error) {
 			return nil, fmt.Errorf("failed to lookup hostname by ip for %s: %w", s.scans[i].IP, err)
 		}
 		s.scans[i].Host = hostname
+
+		sort.Ints(s.scans[i].Ports)
+		guess := osfp.Match(osfp.Signals{
+			TTL:       s.ttls[s.scans[i].IP],
+			SYNACK:    capture.SYNACK(net.ParseIP(s.scans[i].IP), s.scans[i].Ports...),
+			OpenPorts: s.scans[i].Ports,
+		})
+		s.scans[i].OS = &guess
 	}
 	return s.scans, nil
 }
@@ -0,0 +1,53 @@
+package socket
+
+import (
+	"fmt"
+)
+
+const (
+	ProtocolTCP  = "tcp"
+	ProtocolTCP6 = "tcp6"
+	ProtocolUDP  = "udp"
+	ProtocolUDP6 = "udp6"
+	ProtocolUnix = "unix"
+)
+
+// Socket is a socket opened by a local process.
+type Socket struct {
+	Protocol string `json:"protocol" yaml:"protocol" table:"PROTOCOL"`
+	State    string `json:"state" yaml:"state" table:"STATE"`
+	// Local and Remote are the addresses the socket is bound and connected to. Ports that aren't set are shown as *.
+	// The remote address of unix sockets is always empty and their local address is empty if they're unnamed.
+	Local  string `json:"local" yaml:"local" table:"LOCAL"`
+	Remote string `json:"remote,omitempty" yaml:"remote,omitempty" table:"REMOTE"`
+	// RecvQ and SendQ are the bytes waiting to be read and acknowledged.
+	// RecvQ is the number of connections waiting to be accepted instead for listening tcp sockets.
+	RecvQ uint64 `json:"recv_q" yaml:"recv_q" table:"RECV_Q"`
+	SendQ uint64 `json:"send_q" yaml:"send_q" table:"SEND_Q"`
+	Inode uint64 `json:"inode" yaml:"inode" table:"-"`
+	// Process is nil if the socket is owned by a process of another user and the current user isn't root.
+	Process *Process `json:"process,omitempty" yaml:"process,omitempty" table:"PROCESS"`
+}
+
+// Process is a process that has a socket open.
+type Process struct {
+	PID  int    `json:"pid" yaml:"pid"`
+	Name string `json:"name" yaml:"name"`
+}
+
+func (p *Process) String() string {
+	if p == nil {
+		return "N/A"
+	}
+	return fmt.Sprintf("%d/%s", p.PID, p.Name)
+}
+
+// Options configures which sockets are listed.
+type Options struct {
+	// Protocols are the protocols of the sockets listed. Sockets of every protocol are listed if it's empty.
+	Protocols []string
+	// Listening only lists sockets that are waiting for connections, or datagrams if they're udp sockets.
+	Listening bool
+	// Port only lists sockets with a local or remote port of Port if it isn't 0. Unix sockets don't have ports.
+	Port int
+}
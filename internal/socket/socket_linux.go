@@ -0,0 +1,287 @@
+package socket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const procNet = "/proc/net"
+
+// tcp states from include/net/tcp_states.h
+var tcpStates = map[string]string{
+	"01": "ESTABLISHED",
+	"02": "SYN_SENT",
+	"03": "SYN_RECV",
+	"04": "FIN_WAIT1",
+	"05": "FIN_WAIT2",
+	"06": "TIME_WAIT",
+	"07": "CLOSE",
+	"08": "CLOSE_WAIT",
+	"09": "LAST_ACK",
+	"0A": "LISTEN",
+	"0B": "CLOSING",
+	"0C": "NEW_SYN_RECV",
+}
+
+const (
+	tcpListen = "0A"
+	// udpUnconnected is the close state udp sockets are in until they're connected to a remote address.
+	udpUnconnected = "07"
+
+	// unix socket flag set once listen has been called on it.
+	unixAcceptCon = 0x10000
+)
+
+// unix socket states from include/uapi/linux/net.h
+var unixStates = map[string]string{
+	"01": "UNCONNECTED",
+	"02": "CONNECTING",
+	"03": "CONNECTED",
+	"04": "DISCONNECTING",
+}
+
+// List returns the sockets of every process that match opts.
+// Sockets are read from /proc/net and attributed to processes by the inodes of their open file descriptors.
+func List(opts Options) ([]Socket, error) {
+	protocols := opts.Protocols
+	if len(protocols) == 0 {
+		protocols = []string{ProtocolTCP, ProtocolTCP6, ProtocolUDP, ProtocolUDP6, ProtocolUnix}
+	}
+
+	var sockets []Socket
+	for _, protocol := range protocols {
+		path := filepath.Join(procNet, protocol)
+		f, err := os.Open(path)
+		if err != nil {
+			// IPv6 may be disabled so it's not treated as a failure.
+			if os.IsNotExist(err) && (protocol == ProtocolTCP6 || protocol == ProtocolUDP6) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+
+		var s []Socket
+		if protocol == ProtocolUnix {
+			s, err = parseUnix(f, opts)
+		} else {
+			s, err = parseInet(f, protocol, opts)
+		}
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		sockets = append(sockets, s...)
+	}
+
+	owners := processes()
+	for i := range sockets {
+		sockets[i].Process = owners[sockets[i].Inode]
+	}
+	return sockets, nil
+}
+
+// parseInet parses the contents of /proc/net/tcp, tcp6, udp or udp6.
+func parseInet(r io.Reader, protocol string, opts Options) ([]Socket, error) {
+	var (
+		sockets []Socket
+		scanner = bufio.NewScanner(r)
+		isUDP   = protocol == ProtocolUDP || protocol == ProtocolUDP6
+	)
+	for i := 0; scanner.Scan(); i++ {
+		// skip the column headers
+		if i == 0 {
+			continue
+		}
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+
+		local, localPort, err := parseAddr(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		remote, remotePort, err := parseAddr(fields[2])
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.Port != 0 && opts.Port != localPort && opts.Port != remotePort {
+			continue
+		}
+
+		state := tcpStates[fields[3]]
+		listening := fields[3] == tcpListen
+		if isUDP {
+			state = "ESTABLISHED"
+			if listening = fields[3] == udpUnconnected; listening {
+				state = "UNCONNECTED"
+			}
+		}
+		if opts.Listening && !listening {
+			continue
+		}
+
+		sendQ, recvQ, ok := strings.Cut(fields[4], ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid queues %q", fields[4])
+		}
+
+		s := Socket{
+			Protocol: protocol,
+			State:    state,
+			Local:    local,
+			Remote:   remote,
+		}
+		if s.SendQ, err = strconv.ParseUint(sendQ, 16, 64); err != nil {
+			return nil, fmt.Errorf("invalid send queue %q: %w", sendQ, err)
+		}
+		if s.RecvQ, err = strconv.ParseUint(recvQ, 16, 64); err != nil {
+			return nil, fmt.Errorf("invalid receive queue %q: %w", recvQ, err)
+		}
+		if s.Inode, err = strconv.ParseUint(fields[9], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid inode %q: %w", fields[9], err)
+		}
+		sockets = append(sockets, s)
+	}
+	return sockets, scanner.Err()
+}
+
+// parseAddr parses an address formatted as the hex of an ip address, whose 32-bit words are in
+// host byte order, and port separated by a colon.
+func parseAddr(s string) (string, int, error) {
+	hexIP, hexPort, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("invalid address %q", s)
+	}
+
+	b, err := hex.DecodeString(hexIP)
+	if err != nil || (len(b) != net.IPv4len && len(b) != net.IPv6len) {
+		return "", 0, fmt.Errorf("invalid address %q", s)
+	}
+	ip := make(net.IP, len(b))
+	for i := 0; i < len(b); i += 4 {
+		binary.NativeEndian.PutUint32(ip[i:], binary.BigEndian.Uint32(b[i:]))
+	}
+
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port in address %q: %w", s, err)
+	}
+
+	p := "*"
+	if port != 0 {
+		p = strconv.FormatUint(port, 10)
+	}
+	return net.JoinHostPort(ip.String(), p), int(port), nil
+}
+
+// parseUnix parses the contents of /proc/net/unix.
+func parseUnix(r io.Reader, opts Options) ([]Socket, error) {
+	// Unix sockets don't have ports so none of them match.
+	if opts.Port != 0 {
+		return nil, nil
+	}
+
+	var (
+		sockets []Socket
+		scanner = bufio.NewScanner(r)
+	)
+	for i := 0; scanner.Scan(); i++ {
+		// skip the column headers
+		if i == 0 {
+			continue
+		}
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 7 {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid flags %q: %w", fields[3], err)
+		}
+
+		listening := flags&unixAcceptCon != 0
+		if opts.Listening && !listening {
+			continue
+		}
+
+		s := Socket{Protocol: ProtocolUnix, State: unixStates[fields[5]]}
+		if listening {
+			s.State = "LISTEN"
+		}
+		if s.Inode, err = strconv.ParseUint(fields[6], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid inode %q: %w", fields[6], err)
+		}
+		// Paths may contain spaces and abstract socket names start with @.
+		if len(fields) > 7 {
+			s.Local = strings.Join(fields[7:], " ")
+		}
+		sockets = append(sockets, s)
+	}
+	return sockets, scanner.Err()
+}
+
+// processes returns the processes that have each socket open by inode.
+// Processes of other users are skipped if the current user isn't permitted to read their file descriptors.
+func processes() map[uint64]*Process {
+	owners := make(map[uint64]*Process)
+
+	dirs, err := os.ReadDir("/proc")
+	if err != nil {
+		return owners
+	}
+
+	for _, dir := range dirs {
+		pid, err := strconv.Atoi(dir.Name())
+		if err != nil {
+			continue
+		}
+
+		fdDir := filepath.Join("/proc", dir.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+
+		var p *Process
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]"), 10, 64)
+			if err != nil {
+				continue
+			}
+			// Sockets shared by several processes, like those inherited by forked workers, are attributed to the first one.
+			if _, ok := owners[inode]; ok {
+				continue
+			}
+			if p == nil {
+				p = &Process{PID: pid, Name: processName(pid)}
+			}
+			owners[inode] = p
+		}
+	}
+	return owners
+}
+
+func processName(pid int) string {
+	b, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
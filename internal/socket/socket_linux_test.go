@@ -0,0 +1,122 @@
+package socket
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSocket(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("list listening tcp socket and its process", func(t *testing.T) {
+			l, err := net.Listen("tcp4", "127.0.0.1:0")
+			require.NoError(t, err)
+			defer l.Close()
+			port := l.Addr().(*net.TCPAddr).Port
+
+			sockets, err := List(Options{Protocols: []string{ProtocolTCP}, Listening: true, Port: port})
+			require.NoError(t, err)
+			require.Len(t, sockets, 1)
+			require.Equal(t, "LISTEN", sockets[0].State)
+			require.Equal(t, l.Addr().String(), sockets[0].Local)
+			require.Equal(t, "0.0.0.0:*", sockets[0].Remote)
+			require.NotNil(t, sockets[0].Process)
+			require.Equal(t, os.Getpid(), sockets[0].Process.PID)
+		})
+		t.Run("parse tcp sockets", func(t *testing.T) {
+			table := strings.Join([]string{
+				"  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode",
+				fmt.Sprintf("   0: %s %s 0A 00000000:00000080 00:00000000 00000000     0        0 1001 1 0 100 0 0 10 0",
+					hexAddr("0.0.0.0", 22), hexAddr("0.0.0.0", 0)),
+				fmt.Sprintf("   1: %s %s 01 000000A0:00000010 00:00000000 00000000  1000        0 1002 1 0 20 4 30 10 -1",
+					hexAddr("192.168.1.20", 22), hexAddr("192.168.1.5", 51234)),
+			}, "\n")
+
+			sockets, err := parseInet(strings.NewReader(table), ProtocolTCP, Options{})
+			require.NoError(t, err)
+			require.Len(t, sockets, 2)
+			require.Equal(t, Socket{Protocol: ProtocolTCP, State: "LISTEN", Local: "0.0.0.0:22", Remote: "0.0.0.0:*", RecvQ: 128, Inode: 1001}, sockets[0])
+			require.Equal(t, Socket{Protocol: ProtocolTCP, State: "ESTABLISHED", Local: "192.168.1.20:22", Remote: "192.168.1.5:51234", RecvQ: 16, SendQ: 160, Inode: 1002}, sockets[1])
+
+			sockets, err = parseInet(strings.NewReader(table), ProtocolTCP, Options{Listening: true})
+			require.NoError(t, err)
+			require.Len(t, sockets, 1)
+
+			sockets, err = parseInet(strings.NewReader(table), ProtocolTCP, Options{Port: 51234})
+			require.NoError(t, err)
+			require.Len(t, sockets, 1)
+			require.Equal(t, uint64(1002), sockets[0].Inode)
+		})
+		t.Run("parse udp6 sockets", func(t *testing.T) {
+			table := strings.Join([]string{
+				"  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops",
+				fmt.Sprintf("   0: %s %s 07 00000000:00000000 00:00000000 00000000   101        0 2001 2 0 0",
+					hexAddr("::1", 53), hexAddr("::", 0)),
+				fmt.Sprintf("   1: %s %s 01 00000000:00000000 00:00000000 00000000  1000        0 2002 2 0 0",
+					hexAddr("fe80::1", 40000), hexAddr("fe80::2", 123)),
+			}, "\n")
+
+			sockets, err := parseInet(strings.NewReader(table), ProtocolUDP6, Options{Listening: true})
+			require.NoError(t, err)
+			require.Len(t, sockets, 1)
+			require.Equal(t, "UNCONNECTED", sockets[0].State)
+			require.Equal(t, "[::1]:53", sockets[0].Local)
+			require.Equal(t, "[::]:*", sockets[0].Remote)
+		})
+		t.Run("parse unix sockets", func(t *testing.T) {
+			table := strings.Join([]string{
+				"Num       RefCount Protocol Flags    Type St Inode Path",
+				"0000000000000000: 00000002 00000000 00010000 0001 01 3001 /run/my app.sock",
+				"0000000000000000: 00000003 00000000 00000000 0001 03 3002",
+				"0000000000000000: 00000002 00000000 00010000 0001 01 3003 @/tmp/.X11-unix/X0",
+			}, "\n")
+
+			sockets, err := parseUnix(strings.NewReader(table), Options{})
+			require.NoError(t, err)
+			require.Len(t, sockets, 3)
+			require.Equal(t, Socket{Protocol: ProtocolUnix, State: "LISTEN", Local: "/run/my app.sock", Inode: 3001}, sockets[0])
+			require.Equal(t, Socket{Protocol: ProtocolUnix, State: "CONNECTED", Inode: 3002}, sockets[1])
+
+			sockets, err = parseUnix(strings.NewReader(table), Options{Listening: true})
+			require.NoError(t, err)
+			require.Len(t, sockets, 2)
+			require.Equal(t, "@/tmp/.X11-unix/X0", sockets[1].Local)
+		})
+		t.Run("format process", func(t *testing.T) {
+			require.Equal(t, "812/sshd", (&Process{PID: 812, Name: "sshd"}).String())
+			require.Equal(t, "N/A", (*Process)(nil).String())
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("parse invalid address", func(t *testing.T) {
+			_, _, err := parseAddr("0100007F")
+			require.Error(t, err)
+			_, _, err = parseAddr("01007F:0016")
+			require.Error(t, err)
+		})
+		t.Run("parse unix sockets while filtering by port", func(t *testing.T) {
+			sockets, err := parseUnix(strings.NewReader("Num RefCount Protocol Flags Type St Inode Path\n0: 2 0 10000 1 1 3001 /run/app.sock"), Options{Port: 80})
+			require.NoError(t, err)
+			require.Empty(t, sockets)
+		})
+	})
+}
+
+// hexAddr formats ip and port the way the kernel does in /proc/net.
+func hexAddr(ip string, port int) string {
+	addr := net.ParseIP(ip)
+	if v4 := addr.To4(); v4 != nil {
+		addr = v4
+	}
+	var s strings.Builder
+	for i := 0; i < len(addr); i += 4 {
+		fmt.Fprintf(&s, "%08X", binary.NativeEndian.Uint32(addr[i:]))
+	}
+	return fmt.Sprintf("%s:%04X", s.String(), port)
+}
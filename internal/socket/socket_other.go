@@ -0,0 +1,13 @@
+//go:build !linux
+
+package socket
+
+import (
+	"errors"
+	"runtime"
+)
+
+// List returns the sockets of every process that match opts.
+func List(Options) ([]Socket, error) {
+	return nil, errors.New("listing sockets is not supported on " + runtime.GOOS)
+}
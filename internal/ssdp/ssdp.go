@@ -0,0 +1,289 @@
+package ssdp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/ipv4"
+)
+
+const (
+	// descriptionTimeout is how long to wait for a device description to be fetched.
+	descriptionTimeout = 3 * time.Second
+	// maxDescriptionSize is the largest device description that will be read.
+	maxDescriptionSize = 1 << 20
+)
+
+var (
+	group = &net.UDPAddr{IP: net.IPv4(239, 255, 255, 250), Port: 1900}
+
+	// client doesn't follow redirects since descriptions are only fetched from the responder itself.
+	client = &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+)
+
+// Device is a UPnP device that responded to an SSDP search.
+type Device struct {
+	IP           net.IP   `json:"ip" yaml:"ip" table:"IP"`
+	FriendlyName string   `json:"friendly_name" yaml:"friendly_name" table:"FRIENDLY_NAME"`
+	Manufacturer string   `json:"manufacturer" yaml:"manufacturer" table:"MANUFACTURER"`
+	Model        string   `json:"model" yaml:"model" table:"MODEL"`
+	DeviceType   string   `json:"device_type" yaml:"device_type" table:"DEVICE_TYPE"`
+	Services     []string `json:"services" yaml:"services" table:"SERVICES"`
+	Server       string   `json:"server,omitempty" yaml:"server,omitempty" table:"-"`
+	Location     string   `json:"location" yaml:"location" table:"-"`
+}
+
+// Discover multicasts an SSDP search for all devices and services and fetches
+// the device description of each responder. Responses are collected until timeout elapses or ctx is done.
+// Searches are sent on each of ifaces or the system default multicast interface if none are provided.
+func Discover(ctx context.Context, timeout time.Duration, ifaces ...net.Interface) ([]Device, error) {
+	responses, err := search(ctx, timeout, ifaces)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		devices []Device
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+
+	for _, r := range responses {
+		wg.Add(1)
+		go func(r response) {
+			defer wg.Done()
+
+			d := Device{
+				IP:       r.ip,
+				Server:   r.server,
+				Location: r.location,
+			}
+			if desc, err := describe(ctx, r.location); err == nil {
+				d.FriendlyName = desc.Device.FriendlyName
+				d.Manufacturer = desc.Device.Manufacturer
+				d.Model = strings.TrimSpace(desc.Device.ModelName + " " + desc.Device.ModelNumber)
+				d.DeviceType = desc.Device.DeviceType
+				d.Services = desc.Device.services()
+			}
+
+			mu.Lock()
+			devices = append(devices, d)
+			mu.Unlock()
+		}(r)
+	}
+	wg.Wait()
+
+	sort.Slice(devices, func(i, j int) bool {
+		if c := bytes.Compare(devices[i].IP.To16(), devices[j].IP.To16()); c != 0 {
+			return c < 0
+		}
+		return devices[i].Location < devices[j].Location
+	})
+	return devices, nil
+}
+
+type response struct {
+	ip       net.IP
+	location string
+	server   string
+}
+
+// search sends an M-SEARCH request and returns a response for each unique device description location.
+func search(ctx context.Context, timeout time.Duration, ifaces []net.Interface) ([]response, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero})
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for ssdp responses: %w", err)
+	}
+	defer conn.Close()
+
+	// responders wait a random duration of up to MX seconds before responding
+	mx := int(timeout / time.Second)
+	if mx < 1 {
+		mx = 1
+	}
+
+	msg := []byte(strings.Join([]string{
+		"M-SEARCH * HTTP/1.1",
+		"HOST: " + group.String(),
+		`MAN: "ssdp:discover"`,
+		fmt.Sprintf("MX: %d", mx),
+		"ST: ssdp:all",
+		"", "",
+	}, "\r\n"))
+
+	if err := send(conn, msg, ifaces); err != nil {
+		return nil, err
+	}
+
+	var (
+		responses []response
+		seen      = make(map[string]bool)
+		buf       = make([]byte, 2048)
+		deadline  = time.Now().Add(timeout)
+	)
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		_ = conn.SetReadDeadline(minTime(deadline, time.Now().Add(100*time.Millisecond)))
+		n, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read ssdp response: %w", err)
+		}
+
+		r, err := parseResponse(buf[:n], addr.IP)
+		if err != nil || seen[r.location] {
+			continue
+		}
+		seen[r.location] = true
+		responses = append(responses, *r)
+	}
+	return responses, nil
+}
+
+func send(conn *net.UDPConn, msg []byte, ifaces []net.Interface) error {
+	if len(ifaces) == 0 {
+		_, err := conn.WriteToUDP(msg, group)
+		return err
+	}
+
+	var (
+		pc   = ipv4.NewPacketConn(conn)
+		errs []error
+	)
+
+	for i := range ifaces {
+		if err := pc.SetMulticastInterface(&ifaces[i]); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := conn.WriteToUDP(msg, group); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Searches only need to make it out of one of the interfaces.
+	if len(errs) == len(ifaces) {
+		return fmt.Errorf("failed to send search: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// parseResponse parses the http response to an M-SEARCH request.
+// Responses with a location that isn't hosted by the responder are rejected
+// so that a spoofed response can't make us fetch arbitrary urls.
+func parseResponse(b []byte, from net.IP) (*response, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(b)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid location: %w", err)
+	}
+
+	if ip := net.ParseIP(location.Hostname()); ip == nil || !ip.Equal(from) {
+		return nil, fmt.Errorf("location %q is not hosted by %s", location, from)
+	}
+
+	return &response{
+		ip:       from,
+		location: location.String(),
+		server:   resp.Header.Get("Server"),
+	}, nil
+}
+
+type description struct {
+	Device device `xml:"device"`
+}
+
+type device struct {
+	DeviceType   string `xml:"deviceType"`
+	FriendlyName string `xml:"friendlyName"`
+	Manufacturer string `xml:"manufacturer"`
+	ModelName    string `xml:"modelName"`
+	ModelNumber  string `xml:"modelNumber"`
+	ServiceList  []struct {
+		ServiceType string `xml:"serviceType"`
+	} `xml:"serviceList>service"`
+	DeviceList []device `xml:"deviceList>device"`
+}
+
+// services returns the service types of d and all of its embedded devices.
+func (d device) services() []string {
+	var services []string
+	for _, svc := range d.ServiceList {
+		services = append(services, svc.ServiceType)
+	}
+	for _, embedded := range d.DeviceList {
+		services = append(services, embedded.services()...)
+	}
+	return services
+}
+
+// describe fetches and parses the device description at location.
+func describe(ctx context.Context, location string) (*description, error) {
+	ctx, cancel := context.WithTimeout(ctx, descriptionTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status from %s: %s", location, resp.Status)
+	}
+	return parseDescription(io.LimitReader(resp.Body, maxDescriptionSize))
+}
+
+func parseDescription(r io.Reader) (*description, error) {
+	var desc description
+	if err := xml.NewDecoder(r).Decode(&desc); err != nil {
+		return nil, fmt.Errorf("failed to decode device description: %w", err)
+	}
+	return &desc, nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
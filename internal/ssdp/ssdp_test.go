@@ -0,0 +1,92 @@
+package ssdp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testDescription = `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+	<device>
+		<deviceType>urn:schemas-upnp-org:device:InternetGatewayDevice:1</deviceType>
+		<friendlyName>Office Router</friendlyName>
+		<manufacturer>Example Networks</manufacturer>
+		<modelName>EX-1000</modelName>
+		<modelNumber>2</modelNumber>
+		<serviceList>
+			<service><serviceType>urn:schemas-upnp-org:service:Layer3Forwarding:1</serviceType></service>
+		</serviceList>
+		<deviceList>
+			<device>
+				<deviceType>urn:schemas-upnp-org:device:WANDevice:1</deviceType>
+				<serviceList>
+					<service><serviceType>urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1</serviceType></service>
+				</serviceList>
+			</device>
+		</deviceList>
+	</device>
+</root>`
+
+func TestSSDP(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("parse search response", func(t *testing.T) {
+			r, err := parseResponse([]byte(searchResponse("http://192.168.1.1:49152/desc.xml")), net.ParseIP("192.168.1.1"))
+			require.NoError(t, err)
+			require.Equal(t, "http://192.168.1.1:49152/desc.xml", r.location)
+			require.Equal(t, "Linux/3.14 UPnP/1.0 Example/1.0", r.server)
+		})
+		t.Run("describe device", func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, testDescription)
+			}))
+			defer srv.Close()
+
+			desc, err := describe(context.Background(), srv.URL)
+			require.NoError(t, err)
+			require.Equal(t, "Office Router", desc.Device.FriendlyName)
+			require.Equal(t, "Example Networks", desc.Device.Manufacturer)
+			require.Equal(t, "EX-1000", desc.Device.ModelName)
+			require.Equal(t, []string{
+				"urn:schemas-upnp-org:service:Layer3Forwarding:1",
+				"urn:schemas-upnp-org:service:WANCommonInterfaceConfig:1",
+			}, desc.Device.services())
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("parse search response with location hosted by another ip", func(t *testing.T) {
+			r, err := parseResponse([]byte(searchResponse("http://10.0.0.1/desc.xml")), net.ParseIP("192.168.1.1"))
+			require.Nil(t, r)
+			require.Error(t, err)
+		})
+		t.Run("parse invalid search response", func(t *testing.T) {
+			r, err := parseResponse([]byte("NOTIFY * HTTP/1.1\r\n\r\n"), net.ParseIP("192.168.1.1"))
+			require.Nil(t, r)
+			require.Error(t, err)
+		})
+		t.Run("parse invalid device description", func(t *testing.T) {
+			desc, err := parseDescription(strings.NewReader("<root>"))
+			require.Nil(t, desc)
+			require.Error(t, err)
+		})
+	})
+}
+
+func searchResponse(location string) string {
+	return strings.Join([]string{
+		"HTTP/1.1 200 OK",
+		"CACHE-CONTROL: max-age=1800",
+		"LOCATION: " + location,
+		"SERVER: Linux/3.14 UPnP/1.0 Example/1.0",
+		"ST: upnp:rootdevice",
+		"USN: uuid:1234::upnp:rootdevice",
+		"", "",
+	}, "\r\n")
+}
@@ -0,0 +1,25 @@
+package test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/fuskovic/networker/v3/internal/config"
+)
+
+// ConfigDir points the user config directory at a temporary directory for the duration of the test.
+// It returns the networker config directory within it which is created so files can be written to it.
+func ConfigDir(t *testing.T) string {
+	t.Helper()
+	tmp := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", tmp)
+	t.Setenv("HOME", tmp)
+	t.Setenv("AppData", tmp)
+
+	dir, err := config.Dir()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	return dir
+}
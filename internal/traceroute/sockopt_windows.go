@@ -0,0 +1,38 @@
+package traceroute
+
+import "syscall"
+
+// bindWithTTL sets the ttl of the socket fd and binds it to an ephemeral port which is returned.
+func bindWithTTL(fd uintptr, isIPv4 bool, ttl int) (int, error) {
+	var (
+		s   = syscall.Handle(fd)
+		err error
+		sa  syscall.Sockaddr = &syscall.SockaddrInet4{}
+	)
+	if isIPv4 {
+		err = syscall.SetsockoptInt(s, syscall.IPPROTO_IP, syscall.IP_TTL, ttl)
+	} else {
+		sa = &syscall.SockaddrInet6{}
+		err = syscall.SetsockoptInt(s, syscall.IPPROTO_IPV6, syscall.IPV6_UNICAST_HOPS, ttl)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := syscall.Bind(s, sa); err != nil {
+		return 0, err
+	}
+	return localPort(syscall.Getsockname(s))
+}
+
+func localPort(sa syscall.Sockaddr, err error) (int, error) {
+	if err != nil {
+		return 0, err
+	}
+	switch a := sa.(type) {
+	case *syscall.SockaddrInet4:
+		return a.Port, nil
+	case *syscall.SockaddrInet6:
+		return a.Port, nil
+	}
+	return 0, syscall.EWINDOWS
+}
@@ -0,0 +1,574 @@
+package traceroute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+
+	"github.com/fuskovic/networker/v3/internal/ping"
+	"github.com/fuskovic/networker/v3/internal/resolve"
+)
+
+const (
+	notAvailable = "N/A"
+	// noReply is the hostname of hops that didn't reply to any probe.
+	noReply = "*"
+)
+
+const (
+	protocolICMP     = 1
+	protocolTCP      = 6
+	protocolUDP      = 17
+	protocolICMPIPv6 = 58
+)
+
+const (
+	// ProtocolUDP probes hops with udp datagrams sent to unlikely to be used ports.
+	ProtocolUDP Protocol = "udp"
+	// ProtocolICMP probes hops with icmp echo requests.
+	ProtocolICMP Protocol = "icmp"
+	// ProtocolTCP probes hops with tcp handshakes which are more likely to be allowed through firewalls.
+	ProtocolTCP Protocol = "tcp"
+)
+
+const (
+	DefaultMaxHops = 30
+	DefaultQueries = 3
+	DefaultTimeout = 2 * time.Second
+	// DefaultUDPPort is the destination port of the first udp probe. Each probe increments it so its reply can be matched.
+	DefaultUDPPort = 33434
+	DefaultTCPPort = 80
+)
+
+// udpPorts is how many destination ports udp probes cycle through. It must exceed the number of outstanding probes.
+const udpPorts = 1024
+
+// ErrTimeout is returned by Probe when there was no reply to a probe.
+var ErrTimeout = errors.New("no reply")
+
+// Protocol is the kind of packet sent to probe each hop.
+type Protocol string
+
+// Options configures how the path to a host is traced.
+type Options struct {
+	// Protocol is the kind of packet sent to probe each hop. Defaults to ProtocolUDP.
+	Protocol Protocol
+	// MaxHops is the largest ttl probed before giving up on reaching the host. Defaults to DefaultMaxHops.
+	MaxHops int
+	// Queries is how many probes are sent to each hop. Defaults to DefaultQueries.
+	Queries int
+	// Timeout is how long to wait for the reply to each probe. Defaults to DefaultTimeout.
+	Timeout time.Duration
+	// Port is the destination port of udp and tcp probes. Defaults to DefaultUDPPort or DefaultTCPPort.
+	Port int
+	// Hostnames resolves the hostname of each hop.
+	Hostnames bool
+	// ASN resolves the autonomous system and internet service provider of each public hop.
+	ASN bool
+}
+
+// Hop is a router, or the host itself, on the path to a host.
+type Hop struct {
+	TTL      int             `json:"ttl" yaml:"ttl" table:"HOP"`
+	IP       net.IP          `json:"ip" yaml:"ip" table:"IP"`
+	Hostname string          `json:"hostname" yaml:"hostname" table:"HOSTNAME"`
+	RTTs     []time.Duration `json:"rtts" yaml:"rtts" table:"RTTS"`
+	ASN      string          `json:"asn,omitempty" yaml:"asn,omitempty" table:"ASN"`
+	ISP      string          `json:"isp,omitempty" yaml:"isp,omitempty" table:"ISP"`
+}
+
+// Reply is the response to a probe.
+type Reply struct {
+	// IP is the address of the hop that replied.
+	IP  net.IP
+	RTT time.Duration
+	// Reached reports whether the reply came from the host being traced.
+	Reached bool
+}
+
+// Trace probes the path to ip with an increasing ttl until ip replies or the maximum number of hops is reached.
+func Trace(ctx context.Context, ip net.IP, opts Options) ([]Hop, error) {
+	t, err := New(ip, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer t.Close()
+
+	var hops []Hop
+	for ttl := 1; ttl <= t.opts.MaxHops; ttl++ {
+		replies := make([]*Reply, t.opts.Queries)
+
+		var wg sync.WaitGroup
+		for q := range replies {
+			wg.Add(1)
+			go func(q int) {
+				defer wg.Done()
+				if reply, err := t.Probe(ctx, ttl); err == nil {
+					replies[q] = reply
+				}
+			}(q)
+		}
+		wg.Wait()
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		ttlHops, reached := group(ttl, replies)
+		hops = append(hops, ttlHops...)
+		if reached {
+			break
+		}
+	}
+
+	annotate(hops, opts)
+	return hops, nil
+}
+
+// group returns a hop for each address that replied to probes sent with ttl in the order they first replied.
+func group(ttl int, replies []*Reply) ([]Hop, bool) {
+	var (
+		hops    []Hop
+		reached bool
+	)
+	for _, reply := range replies {
+		if reply == nil {
+			continue
+		}
+		reached = reached || reply.Reached
+
+		i := 0
+		for ; i < len(hops); i++ {
+			if hops[i].IP.Equal(reply.IP) {
+				break
+			}
+		}
+		if i == len(hops) {
+			hops = append(hops, Hop{TTL: ttl, IP: reply.IP, Hostname: notAvailable})
+		}
+		hops[i].RTTs = append(hops[i].RTTs, reply.RTT.Round(time.Microsecond))
+	}
+
+	if len(hops) == 0 {
+		hops = append(hops, Hop{TTL: ttl, Hostname: noReply})
+	}
+	return hops, reached
+}
+
+// annotate resolves the hostname and service provider of each hop concurrently.
+func annotate(hops []Hop, opts Options) {
+	var wg sync.WaitGroup
+	for i := range hops {
+		if hops[i].IP == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(h *Hop) {
+			defer wg.Done()
+			if opts.Hostnames {
+				h.Hostname = resolve.Hostname(h.IP)
+			}
+			if opts.ASN && !resolve.IsPrivate(&h.IP) {
+				if isp, err := resolve.ServiceProvider(&h.IP); err == nil {
+					h.ASN, h.ISP = isp.AutonomousServiceNumber, isp.Name
+				}
+			}
+		}(&hops[i])
+	}
+	wg.Wait()
+}
+
+// Tracer sends probes with a particular ttl to a host and matches them with the replies of the hops along the way.
+// It's safe for concurrent use.
+type Tracer struct {
+	ip     net.IP
+	isIPv4 bool
+	opts   Options
+	// conn receives the icmp replies to every kind of probe and sends icmp probes.
+	conn *icmp.PacketConn
+	// udp sends udp probes.
+	udp net.PacketConn
+	id  int
+	seq atomic.Int32
+	// writeMu serializes setting the ttl of a socket and writing to it.
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	pending map[int]chan Reply
+	sent    map[int]time.Time
+}
+
+// New initializes a Tracer of ip. Reading the icmp replies of hops requires a raw socket so the user must be privileged.
+func New(ip net.IP, opts Options) (*Tracer, error) {
+	opts = withDefaults(opts)
+
+	network, address := "ip4:icmp", "0.0.0.0"
+	if ip.To4() == nil {
+		network, address = "ip6:ipv6-icmp", "::"
+	}
+	conn, err := icmp.ListenPacket(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open raw icmp socket(tracing requires elevated privileges): %w", err)
+	}
+
+	t := &Tracer{
+		ip:      ip,
+		isIPv4:  ip.To4() != nil,
+		opts:    opts,
+		conn:    conn,
+		id:      rand.Intn(0xffff),
+		pending: make(map[int]chan Reply),
+		sent:    make(map[int]time.Time),
+	}
+
+	if opts.Protocol == ProtocolUDP {
+		network, address = "udp4", "0.0.0.0:0"
+		if !t.isIPv4 {
+			network, address = "udp6", "[::]:0"
+		}
+		if t.udp, err = net.ListenPacket(network, address); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to open udp socket: %w", err)
+		}
+	}
+
+	go t.read()
+	return t, nil
+}
+
+func withDefaults(opts Options) Options {
+	if opts.Protocol == "" {
+		opts.Protocol = ProtocolUDP
+	}
+	if opts.MaxHops <= 0 {
+		opts.MaxHops = DefaultMaxHops
+	}
+	if opts.Queries <= 0 {
+		opts.Queries = DefaultQueries
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Port == 0 && opts.Protocol == ProtocolUDP {
+		opts.Port = DefaultUDPPort
+	}
+	if opts.Port == 0 && opts.Protocol == ProtocolTCP {
+		opts.Port = DefaultTCPPort
+	}
+	return opts
+}
+
+// Close closes the sockets of t.
+func (t *Tracer) Close() error {
+	if t.udp != nil {
+		t.udp.Close()
+	}
+	return t.conn.Close()
+}
+
+// Probe sends a probe with ttl and waits for a reply. ErrTimeout is returned if there wasn't one.
+func (t *Tracer) Probe(ctx context.Context, ttl int) (*Reply, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.opts.Timeout)
+	defer cancel()
+
+	var (
+		seq     = int(t.seq.Add(1))
+		replies = make(chan Reply, 1)
+		// connected receives the result of tcp handshakes which reach the host without an icmp reply.
+		connected = make(chan Reply, 1)
+		key       int
+		err       error
+	)
+
+	switch t.opts.Protocol {
+	case ProtocolICMP:
+		key = seq & 0xffff
+		t.register(key, replies)
+		err = t.sendICMP(ttl, key)
+	case ProtocolUDP:
+		key = (t.opts.Port + seq%udpPorts) & 0xffff
+		t.register(key, replies)
+		err = t.sendUDP(ttl, key)
+	case ProtocolTCP:
+		// The source port identifies tcp probes and it's only known once the socket is bound.
+		key, err = t.sendTCP(ctx, ttl, replies, connected)
+	default:
+		err = fmt.Errorf("unsupported protocol %q", t.opts.Protocol)
+	}
+	defer t.unregister(key)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case reply := <-replies:
+		return &reply, nil
+	case reply := <-connected:
+		return &reply, nil
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ErrTimeout
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (t *Tracer) register(key int, replies chan Reply) {
+	t.mu.Lock()
+	t.pending[key] = replies
+	t.sent[key] = time.Now()
+	t.mu.Unlock()
+}
+
+func (t *Tracer) unregister(key int) {
+	t.mu.Lock()
+	delete(t.pending, key)
+	delete(t.sent, key)
+	t.mu.Unlock()
+}
+
+func (t *Tracer) sendICMP(ttl, seq int) error {
+	var typ icmp.Type = ipv4.ICMPTypeEcho
+	if !t.isIPv4 {
+		typ = ipv6.ICMPTypeEchoRequest
+	}
+
+	req, err := (&icmp.Message{
+		Type: typ,
+		Body: &icmp.Echo{ID: t.id, Seq: seq, Data: []byte("networker")},
+	}).Marshal(nil)
+	if err != nil {
+		return fmt.Errorf("failed to marshal echo request: %w", err)
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if t.isIPv4 {
+		err = t.conn.IPv4PacketConn().SetTTL(ttl)
+	} else {
+		err = t.conn.IPv6PacketConn().SetHopLimit(ttl)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set ttl: %w", err)
+	}
+	if _, err := t.conn.WriteTo(req, &net.IPAddr{IP: t.ip}); err != nil {
+		return fmt.Errorf("failed to send echo request: %w", err)
+	}
+	return nil
+}
+
+func (t *Tracer) sendUDP(ttl, port int) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	var err error
+	if t.isIPv4 {
+		err = ipv4.NewPacketConn(t.udp).SetTTL(ttl)
+	} else {
+		err = ipv6.NewPacketConn(t.udp).SetHopLimit(ttl)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set ttl: %w", err)
+	}
+	if _, err := t.udp.WriteTo([]byte("networker"), &net.UDPAddr{IP: t.ip, Port: port}); err != nil {
+		return fmt.Errorf("failed to send udp probe: %w", err)
+	}
+	return nil
+}
+
+// sendTCP starts a tcp handshake with ttl in the background. The source port of the handshake is returned
+// once it's registered to receive replies. The host was reached if the handshake completes or is refused.
+func (t *Tracer) sendTCP(ctx context.Context, ttl int, replies, connected chan Reply) (int, error) {
+	var (
+		bound = make(chan int, 1)
+		start = time.Now()
+	)
+
+	dialer := net.Dialer{
+		Control: func(network, address string, c syscall.RawConn) error {
+			var (
+				port int
+				err  error
+			)
+			if cerr := c.Control(func(fd uintptr) {
+				port, err = bindWithTTL(fd, t.isIPv4, ttl)
+			}); cerr != nil {
+				return cerr
+			}
+			if err != nil {
+				return err
+			}
+			t.register(port, replies)
+			bound <- port
+			return nil
+		},
+	}
+
+	dialErr := make(chan error, 1)
+	go func() {
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(t.ip.String(), strconv.Itoa(t.opts.Port)))
+		if err == nil {
+			conn.Close()
+		}
+		if err == nil || ping.IsRefused(err) {
+			connected <- Reply{IP: t.ip, RTT: time.Since(start), Reached: true}
+			return
+		}
+		dialErr <- err
+	}()
+
+	select {
+	case port := <-bound:
+		return port, nil
+	case err := <-dialErr:
+		return 0, fmt.Errorf("failed to send tcp probe: %w", err)
+	}
+}
+
+// read matches icmp replies with the probes they're in response to until the socket is closed.
+func (t *Tracer) read() {
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := t.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) || errors.Is(err, os.ErrClosed) {
+				return
+			}
+			continue
+		}
+		at := time.Now()
+
+		key, reached, ok := t.match(buf[:n], addrIP(peer))
+		if !ok {
+			continue
+		}
+
+		t.mu.Lock()
+		replies, sent := t.pending[key], t.sent[key]
+		t.mu.Unlock()
+		if replies == nil {
+			continue
+		}
+
+		select {
+		case replies <- Reply{IP: addrIP(peer), RTT: at.Sub(sent), Reached: reached}:
+		default:
+		}
+	}
+}
+
+// match returns the key of the probe that b is in response to and whether it came from the host being traced.
+func (t *Tracer) match(b []byte, peer net.IP) (int, bool, bool) {
+	proto := protocolICMP
+	if !t.isIPv4 {
+		proto = protocolICMPIPv6
+	}
+	msg, err := icmp.ParseMessage(proto, b)
+	if err != nil {
+		return 0, false, false
+	}
+
+	var data []byte
+	switch body := msg.Body.(type) {
+	case *icmp.Echo:
+		if t.opts.Protocol != ProtocolICMP || body.ID != t.id || !peer.Equal(t.ip) {
+			return 0, false, false
+		}
+		if msg.Type != ipv4.ICMPTypeEchoReply && msg.Type != ipv6.ICMPTypeEchoReply {
+			return 0, false, false
+		}
+		return body.Seq, true, true
+	case *icmp.TimeExceeded:
+		data = body.Data
+	case *icmp.DstUnreach:
+		data = body.Data
+	default:
+		return 0, false, false
+	}
+
+	probe, ok := parseProbe(data)
+	if !ok || !probe.dst.Equal(t.ip) {
+		return 0, false, false
+	}
+
+	reached := peer.Equal(t.ip)
+	switch {
+	case t.opts.Protocol == ProtocolICMP && (probe.protocol == protocolICMP || probe.protocol == protocolICMPIPv6):
+		if probe.id != t.id {
+			return 0, false, false
+		}
+		return probe.seq, reached, true
+	case t.opts.Protocol == ProtocolUDP && probe.protocol == protocolUDP:
+		return probe.dstPort, reached, true
+	case t.opts.Protocol == ProtocolTCP && probe.protocol == protocolTCP:
+		return probe.srcPort, reached, true
+	}
+	return 0, false, false
+}
+
+// probe is the header of a packet quoted by an icmp error.
+type probe struct {
+	protocol int
+	dst      net.IP
+	srcPort  int
+	dstPort  int
+	id       int
+	seq      int
+}
+
+// parseProbe parses the ip header and the first 8 bytes of the payload of the packet quoted by an icmp error.
+func parseProbe(b []byte) (probe, bool) {
+	var (
+		p       probe
+		payload []byte
+	)
+
+	switch {
+	case len(b) >= ipv4.HeaderLen && b[0]>>4 == 4:
+		headerLen := int(b[0]&0x0f) * 4
+		if len(b) < headerLen+8 {
+			return p, false
+		}
+		p.protocol = int(b[9])
+		p.dst = net.IP(b[16:20])
+		payload = b[headerLen:]
+	case len(b) >= ipv6.HeaderLen+8 && b[0]>>4 == 6:
+		p.protocol = int(b[6])
+		p.dst = net.IP(b[24:40])
+		payload = b[ipv6.HeaderLen:]
+	default:
+		return p, false
+	}
+
+	switch p.protocol {
+	case protocolTCP, protocolUDP:
+		p.srcPort = int(payload[0])<<8 | int(payload[1])
+		p.dstPort = int(payload[2])<<8 | int(payload[3])
+	case protocolICMP, protocolICMPIPv6:
+		p.id = int(payload[4])<<8 | int(payload[5])
+		p.seq = int(payload[6])<<8 | int(payload[7])
+	}
+	return p, true
+}
+
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP
+	case *net.IPAddr:
+		return a.IP
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package traceroute
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/icmp"
+)
+
+func TestTraceroute(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("trace loopback address", func(t *testing.T) {
+			if conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0"); err != nil {
+				t.Skipf("raw icmp sockets are not permitted: %s", err)
+			} else {
+				conn.Close()
+			}
+			for _, protocol := range []Protocol{ProtocolUDP, ProtocolICMP} {
+				hops, err := Trace(context.Background(), net.IPv4(127, 0, 0, 1), Options{Protocol: protocol, Queries: 1, Timeout: time.Second})
+				require.NoError(t, err)
+				require.Len(t, hops, 1)
+				require.True(t, hops[0].IP.Equal(net.IPv4(127, 0, 0, 1)))
+				require.Len(t, hops[0].RTTs, 1)
+			}
+		})
+		t.Run("parse udp probe quoted by an icmpv4 error", func(t *testing.T) {
+			b := []byte{
+				0x45, 0, 0, 37, 0, 0, 0, 0, 1, protocolUDP, 0, 0, 192, 0, 2, 2, 198, 51, 100, 7,
+				0xc3, 0x50, 0x82, 0x9b, 0, 17, 0, 0,
+			}
+			p, ok := parseProbe(b)
+			require.True(t, ok)
+			require.Equal(t, protocolUDP, p.protocol)
+			require.Equal(t, "198.51.100.7", p.dst.String())
+			require.Equal(t, 50000, p.srcPort)
+			require.Equal(t, 33435, p.dstPort)
+		})
+		t.Run("parse echo request quoted by an icmpv6 error", func(t *testing.T) {
+			b := make([]byte, 48)
+			b[0] = 0x60
+			b[6] = protocolICMPIPv6
+			copy(b[24:40], net.ParseIP("2001:db8::7"))
+			copy(b[40:], []byte{128, 0, 0, 0, 0x12, 0x34, 0, 9})
+			p, ok := parseProbe(b)
+			require.True(t, ok)
+			require.Equal(t, "2001:db8::7", p.dst.String())
+			require.Equal(t, 0x1234, p.id)
+			require.Equal(t, 9, p.seq)
+		})
+		t.Run("group replies by the address of each hop", func(t *testing.T) {
+			hops, reached := group(4, []*Reply{
+				{IP: net.ParseIP("10.0.0.1"), RTT: time.Millisecond},
+				nil,
+				{IP: net.ParseIP("10.0.0.2"), RTT: 2 * time.Millisecond},
+				{IP: net.ParseIP("10.0.0.1"), RTT: 3 * time.Millisecond},
+			})
+			require.False(t, reached)
+			require.Len(t, hops, 2)
+			require.Equal(t, []time.Duration{time.Millisecond, 3 * time.Millisecond}, hops[0].RTTs)
+			require.Equal(t, 4, hops[1].TTL)
+
+			hops, _ = group(5, []*Reply{nil, nil})
+			require.Equal(t, []Hop{{TTL: 5, Hostname: noReply}}, hops)
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("parse truncated probe", func(t *testing.T) {
+			_, ok := parseProbe([]byte{0x45, 0, 0, 20})
+			require.False(t, ok)
+		})
+	})
+}
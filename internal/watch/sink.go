@@ -0,0 +1,116 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// webhookTimeout is how long to wait for a webhook to respond.
+const webhookTimeout = 10 * time.Second
+
+// Sink is a destination for events.
+type Sink interface {
+	Send(context.Context, Event) error
+}
+
+// NDJSON returns a sink that writes each event to w as a line of json.
+func NDJSON(w io.Writer) Sink {
+	return &ndjsonSink{w: w}
+}
+
+type ndjsonSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *ndjsonSink) Send(_ context.Context, e Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return json.NewEncoder(s.w).Encode(e)
+}
+
+// Exec returns a sink that runs command with the shell for each event.
+// The event is written to the stdin of the command as json and its fields are exported as environment variables.
+func Exec(command string) Sink {
+	return execSink(command)
+}
+
+type execSink string
+
+func (s execSink) Send(ctx context.Context, e Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	cmd := exec.CommandContext(ctx, shell, flag, string(s))
+	cmd.Stdin = bytes.NewReader(b)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"NW_EVENT_TYPE="+string(e.Type),
+		"NW_EVENT_ID="+e.ID,
+		"NW_EVENT_MAC="+e.MAC,
+		"NW_EVENT_IP="+e.IP,
+		"NW_EVENT_PREVIOUS_IP="+e.PreviousIP,
+		"NW_EVENT_HOSTNAME="+e.Hostname,
+		"NW_EVENT_PREVIOUS_HOSTNAME="+e.PreviousHostname,
+		"NW_EVENT_LABEL="+e.Label,
+		"NW_EVENT_VENDOR="+e.Vendor,
+		"NW_EVENT_KIND="+e.Kind,
+		fmt.Sprintf("NW_EVENT_NEW=%t", e.New),
+	)
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run %q: %w", string(s), err)
+	}
+	return nil
+}
+
+// Webhook returns a sink that posts each event to url as json.
+func Webhook(url string) Sink {
+	return webhookSink(url)
+}
+
+type webhookSink string
+
+func (s webhookSink) Send(ctx context.Context, e Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, string(s), bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status from %s: %s", string(s), resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/fuskovic/networker/v3/internal/list"
+)
+
+const (
+	EventJoined          EventType = "joined"
+	EventLeft            EventType = "left"
+	EventIPChanged       EventType = "ip-changed"
+	EventHostnameChanged EventType = "hostname-changed"
+)
+
+// EventType is what happened to a device between two discoveries.
+type EventType string
+
+// Event is a change to the devices on the network.
+type Event struct {
+	Time time.Time `json:"time"`
+	Type EventType `json:"type"`
+	// ID is the hardware address of the device or its ip address if the hardware address isn't known.
+	ID               string `json:"id"`
+	MAC              string `json:"mac,omitempty"`
+	IP               string `json:"ip"`
+	PreviousIP       string `json:"previous_ip,omitempty"`
+	Hostname         string `json:"hostname,omitempty"`
+	PreviousHostname string `json:"previous_hostname,omitempty"`
+	Label            string `json:"label,omitempty"`
+	Vendor           string `json:"vendor,omitempty"`
+	Kind             string `json:"kind"`
+	// New is set on joined events of devices that have never been seen before.
+	New bool `json:"new,omitempty"`
+}
+
+// Watcher periodically discovers devices and sends an event to each of its sinks for every change.
+type Watcher struct {
+	Interval time.Duration
+	Discover func(context.Context) ([]list.Device, error)
+	Sinks    []Sink
+	// Known are the ids of devices that were seen before watching started(e.g. from the inventory).
+	// Devices that are present when watching starts only emit joined events if they aren't known.
+	Known map[string]bool
+	// OnError is called with errors that don't stop the watcher(e.g. a failed discovery or webhook).
+	OnError func(error)
+}
+
+// Run watches for changes until ctx is done.
+func (w *Watcher) Run(ctx context.Context) error {
+	if w.Known == nil {
+		w.Known = make(map[string]bool)
+	}
+
+	var (
+		previous Snapshot
+		ticker   = time.NewTicker(w.Interval)
+	)
+	defer ticker.Stop()
+
+	for {
+		devices, err := w.Discover(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			w.onError(fmt.Errorf("failed to discover devices: %w", err))
+		default:
+			current := NewSnapshot(devices)
+			for _, e := range w.diff(previous, current) {
+				w.send(ctx, e)
+			}
+			previous = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Watcher) diff(previous, current Snapshot) []Event {
+	now := time.Now()
+	if previous == nil {
+		// Only devices that have never been seen before are announced when watching starts.
+		previous = make(Snapshot)
+		for id, d := range current {
+			if w.Known[id] {
+				previous[id] = d
+			}
+		}
+	}
+
+	events := Diff(previous, current, now)
+	for i := range events {
+		if events[i].Type == EventJoined {
+			events[i].New = !w.Known[events[i].ID]
+			w.Known[events[i].ID] = true
+		}
+	}
+	return events
+}
+
+func (w *Watcher) send(ctx context.Context, e Event) {
+	for _, s := range w.Sinks {
+		if err := s.Send(ctx, e); err != nil {
+			w.onError(fmt.Errorf("failed to send %s event for %s: %w", e.Type, e.ID, err))
+		}
+	}
+}
+
+func (w *Watcher) onError(err error) {
+	if w.OnError != nil {
+		w.OnError(err)
+	}
+}
+
+// Snapshot is the devices present on the network keyed by their id.
+type Snapshot map[string]list.Device
+
+// NewSnapshot returns a snapshot of the devices that are present on the network.
+// A hardware address may answer for several ips(e.g. proxy arp or secondary addresses)
+// in which case the lowest ip is kept so it doesn't change with the order devices were discovered in.
+func NewSnapshot(devices []list.Device) Snapshot {
+	s := make(Snapshot)
+	for _, d := range devices {
+		if !d.Present() {
+			continue
+		}
+		if prev, ok := s[id(d)]; ok && bytes.Compare(prev.LocalIP.To16(), d.LocalIP.To16()) <= 0 {
+			continue
+		}
+		s[id(d)] = d
+	}
+	return s
+}
+
+// Diff returns the events that turn previous into current in order of device id.
+// Hostnames that couldn't be resolved in current are carried over from previous
+// since name resolution is best-effort and shouldn't be reported as a change.
+func Diff(previous, current Snapshot, t time.Time) []Event {
+	var events []Event
+	for id, curr := range current {
+		prev, ok := previous[id]
+		if !ok {
+			events = append(events, newEvent(EventJoined, id, curr, t))
+			continue
+		}
+
+		if curr.Hostname == notAvailable && prev.Hostname != notAvailable {
+			curr.Hostname = prev.Hostname
+			current[id] = curr
+		}
+
+		if !curr.LocalIP.Equal(prev.LocalIP) {
+			e := newEvent(EventIPChanged, id, curr, t)
+			e.PreviousIP = prev.LocalIP.String()
+			events = append(events, e)
+		}
+
+		if curr.Hostname != prev.Hostname && prev.Hostname != notAvailable {
+			e := newEvent(EventHostnameChanged, id, curr, t)
+			e.PreviousHostname = prev.Hostname
+			events = append(events, e)
+		}
+	}
+
+	for id, prev := range previous {
+		if _, ok := current[id]; !ok {
+			events = append(events, newEvent(EventLeft, id, prev, t))
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].ID < events[j].ID
+	})
+	return events
+}
+
+const notAvailable = "N/A"
+
+func newEvent(typ EventType, id string, d list.Device, t time.Time) Event {
+	e := Event{
+		Time:   t,
+		Type:   typ,
+		ID:     id,
+		MAC:    d.MAC,
+		IP:     d.LocalIP.String(),
+		Label:  d.Label,
+		Vendor: d.Vendor,
+		Kind:   string(d.Kind),
+	}
+	if d.Hostname != notAvailable {
+		e.Hostname = d.Hostname
+	}
+	return e
+}
+
+// id returns the hardware address of d or its ip address if the hardware address isn't known.
+func id(d list.Device) string {
+	if d.MAC != "" {
+		return d.MAC
+	}
+	return d.LocalIP.String()
+}
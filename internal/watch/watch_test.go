@@ -0,0 +1,143 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/fuskovic/networker/v3/internal/list"
+)
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	nas := list.Device{Kind: list.DeviceKindNAS, Hostname: "nas.lan.", LocalIP: net.ParseIP("192.168.1.23"), MAC: "00:11:32:aa:bb:cc", Up: true}
+	phone := list.Device{Kind: list.DeviceKindPeer, Hostname: "N/A", LocalIP: net.ParseIP("192.168.1.50"), Up: true}
+	down := list.Device{Kind: list.DeviceKindPeer, Hostname: "N/A", LocalIP: net.ParseIP("192.168.1.60")}
+
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("diff devices that joined and left", func(t *testing.T) {
+			events := Diff(NewSnapshot([]list.Device{nas}), NewSnapshot([]list.Device{phone, down}), time.Now())
+			require.Len(t, events, 2)
+			require.Equal(t, EventLeft, events[0].Type)
+			require.Equal(t, "00:11:32:aa:bb:cc", events[0].ID)
+			require.Equal(t, EventJoined, events[1].Type)
+			require.Equal(t, "192.168.1.50", events[1].ID)
+			require.Empty(t, events[1].Hostname)
+		})
+		t.Run("diff device that changed ip and hostname", func(t *testing.T) {
+			moved := nas
+			moved.LocalIP = net.ParseIP("192.168.1.42")
+			moved.Hostname = "storage.lan."
+
+			events := Diff(NewSnapshot([]list.Device{nas}), NewSnapshot([]list.Device{moved}), time.Now())
+			require.Len(t, events, 2)
+			require.Equal(t, EventIPChanged, events[0].Type)
+			require.Equal(t, "192.168.1.23", events[0].PreviousIP)
+			require.Equal(t, "192.168.1.42", events[0].IP)
+			require.Equal(t, EventHostnameChanged, events[1].Type)
+			require.Equal(t, "nas.lan.", events[1].PreviousHostname)
+			require.Equal(t, "storage.lan.", events[1].Hostname)
+		})
+		t.Run("diff device whose hostname couldn't be resolved", func(t *testing.T) {
+			unresolved := nas
+			unresolved.Hostname = "N/A"
+
+			current := NewSnapshot([]list.Device{unresolved})
+			require.Empty(t, Diff(NewSnapshot([]list.Device{nas}), current, time.Now()))
+			require.Equal(t, "nas.lan.", current["00:11:32:aa:bb:cc"].Hostname)
+		})
+		t.Run("diff device with several ips in any order", func(t *testing.T) {
+			alias := nas
+			alias.LocalIP = net.ParseIP("192.168.1.3")
+
+			previous := NewSnapshot([]list.Device{nas, alias})
+			current := NewSnapshot([]list.Device{alias, nas})
+			require.Empty(t, Diff(previous, current, time.Now()))
+			require.Equal(t, "192.168.1.3", current["00:11:32:aa:bb:cc"].LocalIP.String())
+		})
+		t.Run("run watcher and send events to sinks", func(t *testing.T) {
+			var (
+				webhookEvents = make(chan Event, 10)
+				// Errors are reported by the webhook handler and watcher on other goroutines
+				// so they're asserted on once the watcher has stopped.
+				errs = make(chan error, 10)
+				buf  bytes.Buffer
+				runs int
+			)
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var e Event
+				if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+					errs <- err
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				webhookEvents <- e
+			}))
+			defer srv.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			w := &Watcher{
+				Interval: 10 * time.Millisecond,
+				Sinks:    []Sink{NDJSON(&buf), Webhook(srv.URL)},
+				Known:    map[string]bool{"00:11:32:aa:bb:cc": true},
+				Discover: func(context.Context) ([]list.Device, error) {
+					runs++
+					if runs == 2 {
+						cancel()
+					}
+					return []list.Device{nas, phone}, nil
+				},
+				OnError: func(err error) {
+					errs <- err
+				},
+			}
+			require.NoError(t, w.Run(ctx))
+			close(errs)
+			for err := range errs {
+				require.NoError(t, err)
+			}
+
+			// The known nas is present when watching starts so only the phone is announced.
+			e := <-webhookEvents
+			require.Equal(t, EventJoined, e.Type)
+			require.Equal(t, "192.168.1.50", e.ID)
+			require.True(t, e.New)
+			require.Empty(t, webhookEvents)
+
+			var logged Event
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &logged))
+			require.Equal(t, e.ID, logged.ID)
+		})
+		t.Run("send event to exec hook", func(t *testing.T) {
+			if runtime.GOOS == "windows" {
+				t.Skip("the hook uses sh and grep")
+			}
+			err := Exec(`test "$NW_EVENT_TYPE" = joined && grep -q '"type":"joined"'`).Send(context.Background(), Event{Type: EventJoined})
+			require.NoError(t, err)
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("send event to webhook that rejects it", func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer srv.Close()
+			require.Error(t, Webhook(srv.URL).Send(context.Background(), Event{Type: EventLeft}))
+		})
+		t.Run("send event to failing exec hook", func(t *testing.T) {
+			require.Error(t, Exec("exit 1").Send(context.Background(), Event{Type: EventLeft}))
+		})
+	})
+}
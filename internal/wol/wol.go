@@ -0,0 +1,153 @@
+package wol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DefaultPort is the discard port magic packets are conventionally sent to.
+const DefaultPort = 9
+
+// Target is a broadcast address a magic packet is sent to and the local address it's sent from.
+type Target struct {
+	Interface string
+	Local     net.IP
+	Broadcast net.IP
+}
+
+// MagicPacket returns a magic packet that wakes the device with mac.
+// The password is appended for devices that require a SecureOn password.
+func MagicPacket(mac net.HardwareAddr, password []byte) ([]byte, error) {
+	if len(mac) != 6 {
+		return nil, fmt.Errorf("%s is not an ethernet hardware address", mac)
+	}
+	if len(password) != 0 && len(password) != 4 && len(password) != 6 {
+		return nil, errors.New("secureon password must be 4 or 6 bytes")
+	}
+
+	packet := bytes.Repeat([]byte{0xff}, 6)
+	for i := 0; i < 16; i++ {
+		packet = append(packet, mac...)
+	}
+	return append(packet, password...), nil
+}
+
+// ParsePassword parses a SecureOn password formatted as either a hardware address(6 bytes)
+// or an ipv4 address(4 bytes) like etherwake does.
+func ParsePassword(s string) ([]byte, error) {
+	if mac, err := net.ParseMAC(s); err == nil && len(mac) == 6 {
+		return mac, nil
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid secureon password %q", s)
+	}
+
+	password := make([]byte, 4)
+	for i, part := range parts {
+		b, err := strconv.ParseUint(part, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secureon password %q", s)
+		}
+		password[i] = byte(b)
+	}
+	return password, nil
+}
+
+// Targets returns where to broadcast a magic packet for a device.
+// If ip is known only the network of the interface it's on is targeted, otherwise every network
+// of the named interface or of every interface that is up is targeted.
+func Targets(ip net.IP, iface string) ([]Target, error) {
+	var ifaces []net.Interface
+	if iface != "" {
+		i, err := net.InterfaceByName(iface)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find interface %q: %w", iface, err)
+		}
+		ifaces = []net.Interface{*i}
+	} else {
+		all, err := net.Interfaces()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list interfaces: %w", err)
+		}
+		for _, i := range all {
+			if i.Flags&net.FlagUp != 0 && i.Flags&net.FlagLoopback == 0 && i.Flags&net.FlagBroadcast != 0 {
+				ifaces = append(ifaces, i)
+			}
+		}
+	}
+
+	var targets, matches []Target
+	for _, i := range ifaces {
+		addrs, err := i.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.To4() == nil {
+				continue
+			}
+			subnet := &net.IPNet{IP: ipNet.IP.To4().Mask(ipNet.Mask), Mask: ipNet.Mask}
+			t := Target{
+				Interface: i.Name,
+				Local:     ipNet.IP.To4(),
+				Broadcast: broadcast(subnet),
+			}
+			targets = append(targets, t)
+			if ip != nil && subnet.Contains(ip) {
+				matches = append(matches, t)
+			}
+		}
+	}
+
+	if len(matches) > 0 {
+		return matches, nil
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("no interfaces with an ipv4 address to broadcast on")
+	}
+	return targets, nil
+}
+
+// Send broadcasts packet to each of targets. Magic packets only need to reach the device from
+// one of the targets so an error is only returned if none of them could be sent to.
+func Send(packet []byte, targets []Target, port int) error {
+	var errs []error
+	for _, t := range targets {
+		if err := send(packet, t, port); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.Interface, err))
+		}
+	}
+
+	if len(errs) == len(targets) {
+		return fmt.Errorf("failed to send magic packet: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func send(packet []byte, t Target, port int) error {
+	// Go enables broadcasts on ipv4 udp sockets by default.
+	conn, err := net.DialUDP("udp4", &net.UDPAddr{IP: t.Local}, &net.UDPAddr{IP: t.Broadcast, Port: port})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write(packet)
+	return err
+}
+
+// broadcast returns the last address in network.
+func broadcast(network *net.IPNet) net.IP {
+	ip := make(net.IP, len(network.IP))
+	for i := range network.IP {
+		ip[i] = network.IP[i] | ^network.Mask[i]
+	}
+	return ip
+}
@@ -0,0 +1,72 @@
+package wol
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWOL(t *testing.T) {
+	t.Parallel()
+	t.Run("ShouldPass", func(t *testing.T) {
+		t.Run("build magic packet with secureon password", func(t *testing.T) {
+			mac, _ := net.ParseMAC("00:11:32:aa:bb:cc")
+			password, err := ParsePassword("11:22:33:44:55:66")
+			require.NoError(t, err)
+
+			packet, err := MagicPacket(mac, password)
+			require.NoError(t, err)
+			require.Len(t, packet, 6+16*6+6)
+			require.Equal(t, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, packet[:6])
+			require.Equal(t, []byte(mac), packet[6+15*6:6+16*6])
+			require.Equal(t, password, packet[len(packet)-6:])
+		})
+		t.Run("parse ipv4 formatted secureon password", func(t *testing.T) {
+			password, err := ParsePassword("192.168.0.1")
+			require.NoError(t, err)
+			require.Equal(t, []byte{192, 168, 0, 1}, password)
+		})
+		t.Run("send magic packet to target", func(t *testing.T) {
+			conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+			require.NoError(t, err)
+			defer conn.Close()
+
+			mac, _ := net.ParseMAC("00:11:32:aa:bb:cc")
+			packet, err := MagicPacket(mac, nil)
+			require.NoError(t, err)
+
+			target := Target{Interface: "lo", Local: net.IPv4(127, 0, 0, 1), Broadcast: net.IPv4(127, 0, 0, 1)}
+			require.NoError(t, Send(packet, []Target{target}, conn.LocalAddr().(*net.UDPAddr).Port))
+
+			buf := make([]byte, 1024)
+			require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+			n, _, err := conn.ReadFromUDP(buf)
+			require.NoError(t, err)
+			require.Equal(t, packet, buf[:n])
+		})
+		t.Run("compute broadcast address", func(t *testing.T) {
+			_, subnet, _ := net.ParseCIDR("192.168.4.0/22")
+			require.Equal(t, "192.168.7.255", broadcast(subnet).String())
+		})
+	})
+	t.Run("ShouldFail", func(t *testing.T) {
+		t.Run("build magic packet for non-ethernet hardware address", func(t *testing.T) {
+			mac, _ := net.ParseMAC("00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:01")
+			packet, err := MagicPacket(mac, nil)
+			require.Nil(t, packet)
+			require.Error(t, err)
+		})
+		t.Run("parse invalid secureon password", func(t *testing.T) {
+			password, err := ParsePassword("hunter2")
+			require.Nil(t, password)
+			require.Error(t, err)
+		})
+		t.Run("get targets of unknown interface", func(t *testing.T) {
+			targets, err := Targets(nil, "does-not-exist")
+			require.Nil(t, targets)
+			require.Error(t, err)
+		})
+	})
+}
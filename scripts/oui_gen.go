@@ -0,0 +1,64 @@
+//go:build ignore
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/fuskovic/networker/v3/internal/oui"
+)
+
+// registry is the IEEE MA-L registry embedded in networker.
+const registry = "https://standards-oui.ieee.org/oui/oui.csv"
+
+var projectRoot string
+
+func init() {
+	output, _ := exec.Command("git", "rev-parse", "--show-toplevel").CombinedOutput()
+	projectRoot = strings.Replace(string(output), "\n", "", 1)
+}
+
+func main() {
+	resp, err := http.Get(registry)
+	if err != nil {
+		log.Fatalf("download %s: %v\n", registry, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("download %s: unexpected status %s\n", registry, resp.Status)
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatalf("read %s: %v\n", registry, err)
+	}
+
+	db, err := oui.Parse(bytes.NewReader(b))
+	if err != nil {
+		log.Fatalf("parse %s: %v\n", registry, err)
+	}
+
+	var buf bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if _, err := zw.Write(b); err != nil {
+		log.Fatalf("compress registry: %v\n", err)
+	}
+	if err := zw.Close(); err != nil {
+		log.Fatalf("compress registry: %v\n", err)
+	}
+
+	dst := path.Join(projectRoot, "internal", "oui", "oui.csv.gz")
+	if err := os.WriteFile(dst, buf.Bytes(), 0o644); err != nil {
+		log.Fatalf("write %s: %v\n", dst, err)
+	}
+	log.Printf("embedded %d assignments in %s\n", len(db), dst)
+}